@@ -32,8 +32,16 @@ import (
 func main() {
 	var metricsAddr string
 	var mode string
+	var enableWebhook bool
+	var webhookPort int
+	var webhookCertDir string
+	var trackerImage string
 	flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&mode, "mode", "local", "The mode in which xgboost-operator to run")
+	flag.BoolVar(&enableWebhook, "enable-webhook", false, "Serve the XGBoostJob admission webhooks, it requires a serving certificate in webhook-cert-dir")
+	flag.IntVar(&webhookPort, "webhook-port", 9876, "The port the webhook server binds to.")
+	flag.StringVar(&webhookCertDir, "webhook-cert-dir", "/tmp/cert", "The directory that contains the tls.crt and tls.key of the webhook server.")
+	flag.StringVar(&trackerImage, "tracker-image", "", "The default image of the Rabit tracker sidecar, usually the image of the operator.")
 	flag.Parse()
 	logf.SetLogger(logf.ZapLogger(false))
 	log := logf.Log.WithName("entrypoint")
@@ -48,7 +56,11 @@ func main() {
 
 	// Create a new Cmd to provide shared dependencies and start components
 	log.Info("setting up manager")
-	mgr, err := manager.New(cfg, manager.Options{MetricsBindAddress: metricsAddr})
+	mgr, err := manager.New(cfg, manager.Options{
+		MetricsBindAddress: metricsAddr,
+		Port:               webhookPort,
+		CertDir:            webhookCertDir,
+	})
 	if err != nil {
 		log.Error(err, "unable to set up overall controller manager")
 		os.Exit(1)
@@ -70,10 +82,12 @@ func main() {
 		os.Exit(1)
 	}
 
-	log.Info("setting up webhooks")
-	if err := webhook.AddToManager(mgr); err != nil {
-		log.Error(err, "unable to register webhooks to the manager")
-		os.Exit(1)
+	if enableWebhook {
+		log.Info("setting up webhooks")
+		if err := webhook.AddToManager(mgr); err != nil {
+			log.Error(err, "unable to register webhooks to the manager")
+			os.Exit(1)
+		}
 	}
 
 	// Start the Cmd
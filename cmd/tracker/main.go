@@ -0,0 +1,138 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"flag"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/kubeflow/xgboost-operator/pkg/tracker"
+	"github.com/sirupsen/logrus"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// envInt returns the integer value of the environment variable name, or def if it is not set.
+func envInt(name string, def int) int {
+	if v, err := strconv.Atoi(os.Getenv(name)); err == nil {
+		return v
+	}
+	return def
+}
+
+// newMetricsReporter returns the reporter of the training metrics on the given pod, or nil
+// if there is no pod to annotate. The training goes on without reporting if the tracker
+// cannot reach the API server.
+func newMetricsReporter(namespace, name string, interval time.Duration) *tracker.MetricsReporter {
+	if namespace == "" || name == "" {
+		return nil
+	}
+	config, err := rest.InClusterConfig()
+	if err == nil {
+		var client kubernetes.Interface
+		if client, err = kubernetes.NewForConfig(config); err == nil {
+			return tracker.NewMetricsReporter(client, namespace, name, interval)
+		}
+	}
+	logrus.Warnf("unable to report the training metrics: %v", err)
+	return nil
+}
+
+// reportEvalLog copies the lines of the file path, or of the standard input if path is -,
+// to the standard output, and reports the training metrics of its evaluation lines.
+func reportEvalLog(path, namespace, name string, interval time.Duration) error {
+	in := os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	reporter := newMetricsReporter(namespace, name, interval)
+	if reporter == nil {
+		return tracker.ReportEvalLog(in, os.Stdout, func(int, map[string]float64) {})
+	}
+	stop := make(chan struct{})
+	reported := make(chan struct{})
+	go func() {
+		reporter.Run(stop)
+		close(reported)
+	}()
+	err := tracker.ReportEvalLog(in, os.Stdout, reporter.Report)
+	// Report the metrics of the last iterations before exiting.
+	close(stop)
+	<-reported
+	return err
+}
+
+func main() {
+	var host string
+	var port int
+	var numWorkers int
+	var podName string
+	var podNamespace string
+	var reportInterval time.Duration
+	var evalLog string
+	// The defaults are the variables injected by the operator in every XGBoostJob container.
+	flag.StringVar(&host, "host", "0.0.0.0", "The address the tracker binds to.")
+	flag.IntVar(&port, "port", envInt("DMLC_TRACKER_PORT", 9091), "The port the tracker binds to.")
+	flag.IntVar(&numWorkers, "num-workers", envInt("DMLC_NUM_WORKER", 1), "The number of workers of the job.")
+	// The operator injects the pod of the tracker sidecar, which is annotated with the training metrics.
+	flag.StringVar(&podName, "pod-name", os.Getenv("POD_NAME"), "The pod annotated with the training metrics, none if empty.")
+	flag.StringVar(&podNamespace, "pod-namespace", os.Getenv("POD_NAMESPACE"), "The namespace of the pod annotated with the training metrics.")
+	flag.DurationVar(&reportInterval, "report-interval", 10*time.Second, "The minimum interval between two annotations of the training metrics.")
+	// LightGBM runs no tracker, the coordinator pipes its output through this command instead.
+	flag.StringVar(&evalLog, "eval-log", "", "Report the evaluation lines of this file, - for the standard input, instead of running a tracker.")
+	flag.Parse()
+
+	if evalLog != "" {
+		if err := reportEvalLog(evalLog, podNamespace, podName, reportInterval); err != nil {
+			logrus.Fatalf("unable to report the evaluation log: %v", err)
+		}
+		return
+	}
+
+	t, err := tracker.New(net.JoinHostPort(host, strconv.Itoa(port)), numWorkers)
+	if err != nil {
+		logrus.Fatalf("unable to start tracker: %v", err)
+	}
+
+	stop := make(chan struct{})
+	reported := make(chan struct{})
+	if reporter := newMetricsReporter(podNamespace, podName, reportInterval); reporter != nil {
+		t.OnEval = reporter.Report
+		go func() {
+			reporter.Run(stop)
+			close(reported)
+		}()
+	} else {
+		close(reported)
+	}
+
+	err = t.Run()
+	// Report the metrics of the last iterations before exiting.
+	close(stop)
+	<-reported
+	if err != nil {
+		logrus.Fatalf("tracker failed: %v", err)
+	}
+}
@@ -16,7 +16,11 @@ import argparse
 
 from train import train
 
-from utils import generate_machine_list_file, generate_train_conf_file
+from utils import (
+    generate_machine_list_file,
+    generate_train_conf_file,
+    resolve_machine_list_file,
+)
 
 
 logger = logging.getLogger(__name__)
@@ -47,9 +51,15 @@ def main(args, extra_args):
     elif args.job_type == "Train":
         logging.info("starting the train job")
         logging.info(f"extra args:\n {extra_args}")
-        machine_list_filepath = generate_machine_list_file(
-            master_addr, master_port, worker_addrs, worker_port
-        )
+        # Jobs with `framework: LightGBM` get the machine list from the operator.
+        if "LIGHTGBM_MACHINE_LIST_FILE" in os.environ:
+            machine_list_filepath = resolve_machine_list_file(
+                os.environ["LIGHTGBM_MACHINE_LIST_FILE"]
+            )
+        else:
+            machine_list_filepath = generate_machine_list_file(
+                master_addr, master_port, worker_addrs, worker_port
+            )
         logging.info(f"machine list generated in: {machine_list_filepath}")
         local_port = worker_port if rank else master_port
         config_file = generate_train_conf_file(
@@ -66,6 +66,35 @@ def generate_machine_list_file(
     return filename
 
 
+def resolve_machine_list_file(
+    machine_list_file: str, max_retries: int = 10, sleep_secs: int = 10
+) -> str:
+    """Resolve the hosts of the machine list mounted by the operator.
+
+    LightGBM finds its own rank by matching the local IP addresses, so the
+    service names of the replicas are replaced with their IP address.
+    """
+    filename = tempfile.NamedTemporaryFile(delete=False).name
+
+    def _resolve(host, current_retry=0):
+        try:
+            return socket.gethostbyname(host)
+        except socket.gaierror:
+            if current_retry < max_retries:
+                sleep(sleep_secs)
+                return _resolve(host, current_retry + 1)
+            raise ValueError(f"Couldn't resolve {host}")
+
+    with open(machine_list_file) as src, open(filename, "w") as file:
+        for line in src:
+            if not line.strip():
+                continue
+            host, port = line.split()
+            print(f"{_resolve(host)} {port}", file=file)
+
+    return filename
+
+
 def generate_train_conf_file(
     machine_list_file: str,
     world_size: int,
@@ -6,6 +6,7 @@ require (
 	cloud.google.com/go v0.39.0 // indirect
 	github.com/go-logr/zapr v0.1.1 // indirect
 	github.com/kubeflow/common v0.3.1
+	github.com/prometheus/client_golang v1.5.1
 	github.com/sirupsen/logrus v1.4.2
 	go.uber.org/atomic v1.4.0 // indirect
 	go.uber.org/zap v1.10.0 // indirect
@@ -32,4 +32,22 @@ const (
 	DefaultContainerName     = "xgboostjob"
 	DefaultContainerPortName = "xgboostjob-port"
 	DefaultPort              = 9999
+
+	// DefaultCheckpointPath is where the checkpoint volume is mounted by default.
+	DefaultCheckpointPath = "/xgboostjob/checkpoint"
+	// LastCheckpointAnnotation is set by the coordinator pod of a job to the path of
+	// its last checkpoint, which the controller then reports in the job status.
+	LastCheckpointAnnotation = "xgboostjob.kubeflow.org/last-checkpoint"
+	// TrainingMetricsAnnotation is set by the coordinator pod of a job, or its tracker
+	// sidecar, to the JSON of its last training iteration and evaluation metrics, which
+	// the controller then reports in the job status.
+	TrainingMetricsAnnotation = "xgboostjob.kubeflow.org/training-metrics"
+	// HeartbeatAnnotation is set by the coordinator pod of a job to the RFC 3339 time it
+	// last made progress, which keeps the job from being failed past its progress deadline.
+	HeartbeatAnnotation = "xgboostjob.kubeflow.org/heartbeat"
+
+	// DefaultOutputPath is where the output claim is mounted by default.
+	DefaultOutputPath = "/xgboostjob/output"
+	// DefaultDataPath is where the data claim is mounted by default.
+	DefaultDataPath = "/xgboostjob/data"
 )
@@ -0,0 +1,161 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	commonv1 "github.com/kubeflow/common/pkg/apis/common/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var (
+	// DefaultCleanPodPolicy is the default CleanPodPolicy of an XGBoostJob.
+	DefaultCleanPodPolicy = commonv1.CleanPodPolicyNone
+	// DefaultTTLSecondsAfterFinished is the default TTLSecondsAfterFinished of an XGBoostJob.
+	DefaultTTLSecondsAfterFinished = int32(100)
+	// DefaultFramework is the default Framework of an XGBoostJob.
+	DefaultFramework = FrameworkXGBoost
+	// DefaultJobType is the default JobType of an XGBoostJob.
+	DefaultJobType = JobTypeTrain
+	// DefaultSuccessPolicy is the default SuccessPolicy of an XGBoostJob.
+	DefaultSuccessPolicy = SuccessPolicyMasterCompleted
+	// DefaultPredictionSuccessPolicy is the default SuccessPolicy of a Predict or Evaluate
+	// XGBoostJob, whose every replica predicts on its shard of the data.
+	DefaultPredictionSuccessPolicy = SuccessPolicyMasterAndAllWorkers
+	// DefaultRestartScope is the default RestartScope of an XGBoostJob.
+	DefaultRestartScope = RestartScopePod
+	// DefaultShardingMode is the default ShardingMode of the data of an XGBoostJob.
+	DefaultShardingMode = ShardingModeByFile
+	// DefaultSweepAlgorithm is the default Algorithm of an XGBoostSweep.
+	DefaultSweepAlgorithm = SweepAlgorithmGrid
+	// DefaultMaxParallelTrials is the default MaxParallelTrials of an XGBoostSweep.
+	DefaultMaxParallelTrials = int32(1)
+	// DefaultObjectiveGoal is the default Goal of the objective of an XGBoostSweep.
+	DefaultObjectiveGoal = ObjectiveGoalMaximize
+	// DefaultRestartPolicies are the default RestartPolicy of each replica type.
+	DefaultRestartPolicies = map[XGBoostJobReplicaType]commonv1.RestartPolicy{
+		XGBoostReplicaTypeMaster: commonv1.RestartPolicyNever,
+		XGBoostReplicaTypeWorker: commonv1.RestartPolicyExitCode,
+	}
+)
+
+func addDefaultingFuncs(scheme *runtime.Scheme) error {
+	scheme.AddTypeDefaultingFunc(&XGBoostJob{}, func(obj interface{}) { SetDefaults_XGBoostJob(obj.(*XGBoostJob)) })
+	scheme.AddTypeDefaultingFunc(&XGBoostSweep{}, func(obj interface{}) { SetDefaults_XGBoostSweep(obj.(*XGBoostSweep)) })
+	return nil
+}
+
+// SetDefaults_XGBoostJob sets any unspecified values to defaults.
+func SetDefaults_XGBoostJob(job *XGBoostJob) {
+	if job.Spec.RunPolicy.CleanPodPolicy == nil {
+		policy := DefaultCleanPodPolicy
+		job.Spec.RunPolicy.CleanPodPolicy = &policy
+	}
+	if job.Spec.RunPolicy.TTLSecondsAfterFinished == nil {
+		ttl := DefaultTTLSecondsAfterFinished
+		job.Spec.RunPolicy.TTLSecondsAfterFinished = &ttl
+	}
+	if job.Spec.Framework == "" {
+		job.Spec.Framework = DefaultFramework
+	}
+	if job.Spec.JobType == "" {
+		job.Spec.JobType = DefaultJobType
+	}
+	if job.Spec.SuccessPolicy == "" {
+		job.Spec.SuccessPolicy = DefaultSuccessPolicy
+		if job.Spec.JobType != JobTypeTrain {
+			job.Spec.SuccessPolicy = DefaultPredictionSuccessPolicy
+		}
+	}
+	if job.Spec.RestartScope == "" {
+		job.Spec.RestartScope = DefaultRestartScope
+	}
+	if job.Spec.Checkpoint != nil && job.Spec.Checkpoint.Path == "" {
+		job.Spec.Checkpoint.Path = DefaultCheckpointPath
+	}
+	if job.Spec.Output != nil && job.Spec.Output.ClaimName != "" && job.Spec.Output.Path == "" {
+		job.Spec.Output.Path = DefaultOutputPath
+	}
+	if job.Spec.Data != nil {
+		if job.Spec.Data.ClaimName != "" && job.Spec.Data.Path == "" {
+			job.Spec.Data.Path = DefaultDataPath
+		}
+		if job.Spec.Data.Sharding == "" {
+			job.Spec.Data.Sharding = DefaultShardingMode
+		}
+		if job.Spec.Data.Download != nil && job.Spec.Data.Download.Destination == "" {
+			job.Spec.Data.Download.Destination = DefaultDataPath
+		}
+	}
+
+	for rtype, spec := range job.Spec.XGBReplicaSpecs {
+		if spec == nil {
+			continue
+		}
+		setDefaultReplicas(spec)
+		setDefaultRestartPolicy(XGBoostJobReplicaType(rtype), spec)
+		setDefaultPort(&spec.Template.Spec)
+	}
+}
+
+// setDefaultReplicas sets the replicas to 1 if not specified.
+func setDefaultReplicas(spec *commonv1.ReplicaSpec) {
+	if spec.Replicas == nil {
+		spec.Replicas = new(int32)
+		*spec.Replicas = 1
+	}
+}
+
+// setDefaultRestartPolicy sets the default restart policy of the replica type.
+func setDefaultRestartPolicy(rtype XGBoostJobReplicaType, spec *commonv1.ReplicaSpec) {
+	if spec.RestartPolicy != "" {
+		return
+	}
+	if policy, ok := DefaultRestartPolicies[rtype]; ok {
+		spec.RestartPolicy = policy
+	}
+}
+
+// setDefaultPort adds the default port to the xgboostjob container if it has none.
+func setDefaultPort(spec *corev1.PodSpec) {
+	for i := range spec.Containers {
+		if spec.Containers[i].Name != DefaultContainerName {
+			continue
+		}
+		for _, port := range spec.Containers[i].Ports {
+			if port.Name == DefaultContainerPortName {
+				return
+			}
+		}
+		spec.Containers[i].Ports = append(spec.Containers[i].Ports, corev1.ContainerPort{
+			Name:          DefaultContainerPortName,
+			ContainerPort: DefaultPort,
+		})
+		return
+	}
+}
+
+// SetDefaults_XGBoostSweep sets any unspecified values to defaults. The template is
+// defaulted in every trial.
+func SetDefaults_XGBoostSweep(sweep *XGBoostSweep) {
+	if sweep.Spec.Algorithm == "" {
+		sweep.Spec.Algorithm = DefaultSweepAlgorithm
+	}
+	if sweep.Spec.MaxParallelTrials == nil {
+		maxParallelTrials := DefaultMaxParallelTrials
+		sweep.Spec.MaxParallelTrials = &maxParallelTrials
+	}
+	if sweep.Spec.Objective.Goal == "" {
+		sweep.Spec.Objective.Goal = DefaultObjectiveGoal
+	}
+}
@@ -0,0 +1,132 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"testing"
+
+	commonv1 "github.com/kubeflow/common/pkg/apis/common/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestSetDefaults_XGBoostJob(t *testing.T) {
+	job := &XGBoostJob{
+		Spec: XGBoostJobSpec{
+			XGBReplicaSpecs: map[commonv1.ReplicaType]*commonv1.ReplicaSpec{
+				commonv1.ReplicaType(XGBoostReplicaTypeMaster): {
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{{Name: DefaultContainerName}},
+						},
+					},
+				},
+				commonv1.ReplicaType(XGBoostReplicaTypeWorker): {
+					RestartPolicy: commonv1.RestartPolicyOnFailure,
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{{
+								Name:  DefaultContainerName,
+								Ports: []corev1.ContainerPort{{Name: DefaultContainerPortName, ContainerPort: 9991}},
+							}},
+						},
+					},
+				},
+			},
+			Checkpoint: &CheckpointSpec{ClaimName: "checkpoints"},
+			Output:     &OutputSpec{ClaimName: "models"},
+			Data:       &DataSpec{URI: "oss://datasets/iris", Download: &DataDownloadSpec{Image: "downloader"}},
+		},
+	}
+
+	SetDefaults_XGBoostJob(job)
+
+	if *job.Spec.RunPolicy.CleanPodPolicy != DefaultCleanPodPolicy {
+		t.Errorf("Got CleanPodPolicy %s. Expected %s", *job.Spec.RunPolicy.CleanPodPolicy, DefaultCleanPodPolicy)
+	}
+	if *job.Spec.RunPolicy.TTLSecondsAfterFinished != DefaultTTLSecondsAfterFinished {
+		t.Errorf("Got TTLSecondsAfterFinished %d. Expected %d", *job.Spec.RunPolicy.TTLSecondsAfterFinished, DefaultTTLSecondsAfterFinished)
+	}
+
+	if job.Spec.Framework != DefaultFramework {
+		t.Errorf("Got Framework %s. Expected %s", job.Spec.Framework, DefaultFramework)
+	}
+	if job.Spec.SuccessPolicy != DefaultSuccessPolicy {
+		t.Errorf("Got SuccessPolicy %s. Expected %s", job.Spec.SuccessPolicy, DefaultSuccessPolicy)
+	}
+	if job.Spec.JobType != DefaultJobType {
+		t.Errorf("Got JobType %s. Expected %s", job.Spec.JobType, DefaultJobType)
+	}
+	if job.Spec.RestartScope != DefaultRestartScope {
+		t.Errorf("Got RestartScope %s. Expected %s", job.Spec.RestartScope, DefaultRestartScope)
+	}
+	if job.Spec.Checkpoint.Path != DefaultCheckpointPath {
+		t.Errorf("Got checkpoint Path %s. Expected %s", job.Spec.Checkpoint.Path, DefaultCheckpointPath)
+	}
+	if job.Spec.Output.Path != DefaultOutputPath {
+		t.Errorf("Got output Path %s. Expected %s", job.Spec.Output.Path, DefaultOutputPath)
+	}
+	if job.Spec.Data.Sharding != DefaultShardingMode {
+		t.Errorf("Got data Sharding %s. Expected %s", job.Spec.Data.Sharding, DefaultShardingMode)
+	}
+	if job.Spec.Data.Path != "" {
+		t.Errorf("Got data Path %s for data in an object store. Expected none", job.Spec.Data.Path)
+	}
+	if job.Spec.Data.Download.Destination != DefaultDataPath {
+		t.Errorf("Got data download Destination %s. Expected %s", job.Spec.Data.Download.Destination, DefaultDataPath)
+	}
+	predict := &XGBoostJob{Spec: XGBoostJobSpec{JobType: JobTypePredict}}
+	SetDefaults_XGBoostJob(predict)
+	if predict.Spec.SuccessPolicy != DefaultPredictionSuccessPolicy {
+		t.Errorf("Got SuccessPolicy %s for a Predict job. Expected %s", predict.Spec.SuccessPolicy, DefaultPredictionSuccessPolicy)
+	}
+	onClaim := &XGBoostJob{Spec: XGBoostJobSpec{Data: &DataSpec{ClaimName: "iris"}}}
+	SetDefaults_XGBoostJob(onClaim)
+	if onClaim.Spec.Data.Path != DefaultDataPath {
+		t.Errorf("Got data Path %s. Expected %s", onClaim.Spec.Data.Path, DefaultDataPath)
+	}
+
+	master := job.Spec.XGBReplicaSpecs[commonv1.ReplicaType(XGBoostReplicaTypeMaster)]
+	if *master.Replicas != 1 {
+		t.Errorf("Got Master replicas %d. Expected 1", *master.Replicas)
+	}
+	if master.RestartPolicy != commonv1.RestartPolicyNever {
+		t.Errorf("Got Master RestartPolicy %s. Expected %s", master.RestartPolicy, commonv1.RestartPolicyNever)
+	}
+	if ports := master.Template.Spec.Containers[0].Ports; len(ports) != 1 || ports[0].ContainerPort != DefaultPort {
+		t.Errorf("Got Master ports %v. Expected the default port %d", ports, DefaultPort)
+	}
+
+	worker := job.Spec.XGBReplicaSpecs[commonv1.ReplicaType(XGBoostReplicaTypeWorker)]
+	if worker.RestartPolicy != commonv1.RestartPolicyOnFailure {
+		t.Errorf("Got Worker RestartPolicy %s. Expected %s", worker.RestartPolicy, commonv1.RestartPolicyOnFailure)
+	}
+	if ports := worker.Template.Spec.Containers[0].Ports; len(ports) != 1 || ports[0].ContainerPort != 9991 {
+		t.Errorf("Got Worker ports %v. Expected the user port 9991", ports)
+	}
+}
+
+func TestSetDefaults_XGBoostSweep(t *testing.T) {
+	sweep := &XGBoostSweep{Spec: XGBoostSweepSpec{Objective: SweepObjective{MetricName: "validation-auc"}}}
+
+	SetDefaults_XGBoostSweep(sweep)
+
+	if sweep.Spec.Algorithm != DefaultSweepAlgorithm {
+		t.Errorf("Got Algorithm %s. Expected %s", sweep.Spec.Algorithm, DefaultSweepAlgorithm)
+	}
+	if *sweep.Spec.MaxParallelTrials != DefaultMaxParallelTrials {
+		t.Errorf("Got MaxParallelTrials %d. Expected %d", *sweep.Spec.MaxParallelTrials, DefaultMaxParallelTrials)
+	}
+	if sweep.Spec.Objective.Goal != DefaultObjectiveGoal {
+		t.Errorf("Got objective Goal %s. Expected %s", sweep.Spec.Objective.Goal, DefaultObjectiveGoal)
+	}
+}
@@ -42,6 +42,10 @@ var (
 	AddToScheme = SchemeBuilder.AddToScheme
 )
 
+func init() {
+	SchemeBuilder.SchemeBuilder.Register(addDefaultingFuncs)
+}
+
 // Resource is required by pkg/client/listers/...
 func Resource(resource string) schema.GroupResource {
 	return SchemeGroupVersion.WithResource(resource).GroupResource()
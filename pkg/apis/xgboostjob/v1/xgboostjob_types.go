@@ -30,6 +30,264 @@ type XGBoostJobSpec struct {
 	RunPolicy commonv1.RunPolicy `json:",inline"`
 
 	XGBReplicaSpecs map[commonv1.ReplicaType]*commonv1.ReplicaSpec `json:"xgbReplicaSpecs"`
+
+	// Framework is the training framework run by the replicas, one of XGBoost and
+	// LightGBM. It decides the cluster configuration given to the pods. Defaults to XGBoost.
+	// +optional
+	Framework Framework `json:"framework,omitempty"`
+
+	// JobType is what the job does, one of Train, Predict and Evaluate. Predict and
+	// Evaluate jobs require ModelFrom. Defaults to Train.
+	// +optional
+	JobType JobType `json:"jobType,omitempty"`
+
+	// ModelFrom is the model given to every replica, e.g. to predict with it.
+	// +optional
+	ModelFrom *ModelSource `json:"modelFrom,omitempty"`
+
+	// SuccessPolicy decides which replicas must complete for the job to succeed, one of
+	// MasterCompleted, AllWorkersCompleted and MasterAndAllWorkers. Defaults to MasterCompleted,
+	// or MasterAndAllWorkers for Predict and Evaluate jobs.
+	// +optional
+	SuccessPolicy SuccessPolicy `json:"successPolicy,omitempty"`
+
+	// RestartScope decides which pods are restarted when replicas with the ExitCode
+	// restart policy fail with a retryable error, one of Pod and Job. Defaults to Pod.
+	// +optional
+	RestartScope RestartScope `json:"restartScope,omitempty"`
+
+	// XGBParams are the training parameters of the job. They are passed to every
+	// container as environment variables and as a mounted JSON file.
+	// +optional
+	XGBParams *XGBoostParams `json:"xgbParams,omitempty"`
+
+	// RabitTracker configures the Rabit tracker the workers connect to.
+	// +optional
+	RabitTracker *RabitTrackerSpec `json:"rabitTracker,omitempty"`
+
+	// Checkpoint configures where the replicas save their checkpoints, so that restarted
+	// replicas resume from the last one.
+	// +optional
+	Checkpoint *CheckpointSpec `json:"checkpoint,omitempty"`
+
+	// Output configures where the coordinator pod saves the trained model.
+	// +optional
+	Output *OutputSpec `json:"output,omitempty"`
+
+	// Data configures the training data, sharded among the replicas by the controller.
+	// +optional
+	Data *DataSpec `json:"data,omitempty"`
+
+	// ProgressDeadlineSeconds is the time the coordinator pod may run without reporting
+	// training metrics or a heartbeat before the job is failed as stalled.
+	// +optional
+	ProgressDeadlineSeconds *int64 `json:"progressDeadlineSeconds,omitempty"`
+
+	// Suspend deletes the pods and services of the job until it is unset, when they are
+	// created again. The restart counts and the last checkpoint of the job are kept.
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+}
+
+// ModelSource is where the model of a job comes from, either a URI or the model saved by
+// another job.
+type ModelSource struct {
+	// URI of the model, an object store location, or pvc://<claim name>/<path> for a
+	// model on a PersistentVolumeClaim, like the model reported by a Train job.
+	// +optional
+	URI string `json:"uri,omitempty"`
+
+	// XGBoostJobRef is the name of an XGBoostJob of the same namespace whose saved model
+	// is used. The pods of the job are created once it has succeeded.
+	// +optional
+	XGBoostJobRef string `json:"xgboostJobRef,omitempty"`
+
+	// SecretName is the name of a Secret whose keys are given as environment variables
+	// to every container, e.g. the credentials of the object store.
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+}
+
+// DataSpec configures the training data of a job, either on a PersistentVolumeClaim
+// mounted read-only in every replica, or under an object store prefix.
+type DataSpec struct {
+	// ClaimName is the name of the PersistentVolumeClaim holding the data.
+	// +optional
+	ClaimName string `json:"claimName,omitempty"`
+
+	// Path is where the claim is mounted in every container. Defaults to /xgboostjob/data.
+	// +optional
+	Path string `json:"path,omitempty"`
+
+	// URI is the object store prefix of the data, used instead of ClaimName, e.g.
+	// oss://bucket/iris.
+	// +optional
+	URI string `json:"uri,omitempty"`
+
+	// Files are the data files, relative to the claim or to the URI.
+	// +optional
+	Files []string `json:"files,omitempty"`
+
+	// Sharding decides how the data is split among the replicas, one of ByFile and
+	// ByRowRange. Defaults to ByFile.
+	// +optional
+	Sharding ShardingMode `json:"sharding,omitempty"`
+
+	// Rows is the number of rows of the data, split among the replicas with the
+	// ByRowRange sharding.
+	// +optional
+	Rows *int64 `json:"rows,omitempty"`
+
+	// Download configures an init container downloading the shard of every replica from
+	// the URI before it starts.
+	// +optional
+	Download *DataDownloadSpec `json:"download,omitempty"`
+}
+
+// DataDownloadSpec configures the init container downloading the data of a replica to
+// an emptyDir volume.
+type DataDownloadSpec struct {
+	// Image of the init container. It downloads the XGBOOST_DATA_FILES, or everything
+	// under XGBOOST_DATA_URI, to XGBOOST_DATA_DIR.
+	Image string `json:"image"`
+
+	// Destination is where the data is downloaded to in every container. Defaults to
+	// /xgboostjob/data.
+	// +optional
+	Destination string `json:"destination,omitempty"`
+
+	// SecretName is the name of a Secret whose keys are given as environment variables
+	// to the init container, e.g. the credentials of the object store.
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+}
+
+// OutputSpec configures where the model of a job is saved, either on a
+// PersistentVolumeClaim mounted in the coordinator pod, or in an object store.
+type OutputSpec struct {
+	// ClaimName is the name of the PersistentVolumeClaim the model is saved to.
+	// +optional
+	ClaimName string `json:"claimName,omitempty"`
+
+	// Path is where the claim is mounted in the coordinator pod. Defaults to
+	// /xgboostjob/output.
+	// +optional
+	Path string `json:"path,omitempty"`
+
+	// SubPath is the directory of the claim mounted at Path. Defaults to the root of the
+	// claim.
+	// +optional
+	SubPath string `json:"subPath,omitempty"`
+
+	// URI is the object store location the model is saved to, used instead of
+	// ClaimName, e.g. oss://bucket/model.json.
+	// +optional
+	URI string `json:"uri,omitempty"`
+
+	// SecretName is the name of a Secret whose keys are given as environment variables
+	// to the coordinator pod, e.g. the credentials of the object store.
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+}
+
+// CheckpointSpec configures the checkpoints of a job. The checkpoint volume is either a
+// PersistentVolumeClaim mounted by the controller, or a volume of the replica templates.
+type CheckpointSpec struct {
+	// ClaimName is the name of the PersistentVolumeClaim holding the checkpoints.
+	// +optional
+	ClaimName string `json:"claimName,omitempty"`
+
+	// VolumeName is the name of the volume of the replica templates holding the
+	// checkpoints, used instead of ClaimName.
+	// +optional
+	VolumeName string `json:"volumeName,omitempty"`
+
+	// Path is where the checkpoint volume is mounted in every container. Defaults to
+	// /xgboostjob/checkpoint.
+	// +optional
+	Path string `json:"path,omitempty"`
+
+	// Interval is a hint of the number of boosting rounds between two checkpoints.
+	// +optional
+	Interval *int32 `json:"interval,omitempty"`
+}
+
+// RabitTrackerSpec configures the Rabit tracker of a job.
+type RabitTrackerSpec struct {
+	// Sidecar runs the tracker built into the operator as a sidecar of the Master pod,
+	// listening on the Master port. The Master container must not start a tracker then.
+	// +optional
+	Sidecar bool `json:"sidecar,omitempty"`
+
+	// Image of the tracker sidecar. Defaults to the tracker image of the operator.
+	// +optional
+	Image string `json:"image,omitempty"`
+}
+
+// XGBoostParams are the XGBoost learning task and booster parameters. See
+// https://xgboost.readthedocs.io/en/latest/parameter.html for their meaning.
+//
+// The real-valued parameters are floats rather than strings or Quantities, as XGBoost reads
+// them from the JSON config and the sweeps sample them from float ranges. They are never
+// compared for equality, and their bounds are enforced by the CRD schema.
+type XGBoostParams struct {
+	// Objective is the learning objective, e.g. "binary:logistic".
+	// +optional
+	Objective string `json:"objective,omitempty"`
+
+	// NumClass is the number of classes of a multi-class objective.
+	// +optional
+	NumClass *int32 `json:"numClass,omitempty"`
+
+	// NumRound is the number of boosting rounds.
+	// +optional
+	NumRound *int32 `json:"numRound,omitempty"`
+
+	// MaxDepth is the maximum depth of a tree.
+	// +optional
+	MaxDepth *int32 `json:"maxDepth,omitempty"`
+
+	// Eta is the learning rate, in (0, 1].
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:ExclusiveMinimum=true
+	// +kubebuilder:validation:Maximum=1
+	// +optional
+	Eta *float64 `json:"eta,omitempty"`
+
+	// Gamma is the minimum loss reduction required to split a leaf.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Gamma *float64 `json:"gamma,omitempty"`
+
+	// Subsample is the ratio of training instances sampled for each tree, in (0, 1].
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:ExclusiveMinimum=true
+	// +kubebuilder:validation:Maximum=1
+	// +optional
+	Subsample *float64 `json:"subsample,omitempty"`
+
+	// ColsampleByTree is the ratio of columns sampled for each tree, in (0, 1].
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:ExclusiveMinimum=true
+	// +kubebuilder:validation:Maximum=1
+	// +optional
+	ColsampleByTree *float64 `json:"colsampleByTree,omitempty"`
+
+	// EvalMetric are the metrics evaluated on the validation data.
+	// +optional
+	EvalMetric []string `json:"evalMetric,omitempty"`
+
+	// TreeMethod is the tree construction algorithm, one of auto, exact, approx, hist and gpu_hist.
+	// +optional
+	TreeMethod string `json:"treeMethod,omitempty"`
+
+	// Seed is the random number seed.
+	// +optional
+	Seed *int32 `json:"seed,omitempty"`
+
+	// Extra holds any other parameter, keyed by its XGBoost name.
+	// +optional
+	Extra map[string]string `json:"extra,omitempty"`
 }
 
 // XGBoostJobStatus defines the observed state of XGBoostJob
@@ -37,6 +295,127 @@ type XGBoostJobStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
 	commonv1.JobStatus `json:",inline"`
+
+	// RestartCounts are the number of times the failed pods of each replica type were
+	// restarted by the controller. They count toward the BackoffLimit of the job.
+	// +optional
+	RestartCounts map[commonv1.ReplicaType]int32 `json:"restartCounts,omitempty"`
+
+	// Attempt is the number of times all the pods of the job were restarted together,
+	// with the Job restart scope. It is given to the pods of the job.
+	// +optional
+	Attempt int32 `json:"attempt,omitempty"`
+
+	// LastCheckpoint is the last checkpoint reported by the coordinator pod of the job.
+	// +optional
+	LastCheckpoint *CheckpointStatus `json:"lastCheckpoint,omitempty"`
+
+	// Model is the model saved by a succeeded job with an output.
+	// +optional
+	Model *ModelStatus `json:"model,omitempty"`
+
+	// DataDownloads is the progress of the data downloads of each replica type.
+	// +optional
+	DataDownloads map[commonv1.ReplicaType]*DataDownloadStatus `json:"dataDownloads,omitempty"`
+
+	// Predictions summarizes the outputs of a succeeded Predict or Evaluate job.
+	// +optional
+	Predictions *PredictionStatus `json:"predictions,omitempty"`
+
+	// InputModel is the URI of the model saved by the XGBoostJob of
+	// modelFrom.xgboostJobRef, once it has succeeded.
+	// +optional
+	InputModel string `json:"inputModel,omitempty"`
+
+	// TrainingMetrics are the last training iteration and evaluation metrics reported by
+	// the coordinator pod of the job.
+	// +optional
+	TrainingMetrics *TrainingMetricsStatus `json:"trainingMetrics,omitempty"`
+}
+
+// TrainingMetricsStatus is the progress of the training reported by the coordinator pod.
+type TrainingMetricsStatus struct {
+	// Iteration is the last boosting round evaluated.
+	Iteration int32 `json:"iteration"`
+
+	// Metrics are the evaluation metrics of the iteration, e.g. eval-auc.
+	// +optional
+	Metrics map[string]string `json:"metrics,omitempty"`
+
+	// ReportTime is when the controller found the iteration.
+	ReportTime metav1.Time `json:"reportTime"`
+}
+
+// PredictionStatus summarizes the outputs of the replicas of a Predict or Evaluate job.
+type PredictionStatus struct {
+	// Rows is the total number of rows predicted by the replicas.
+	// +optional
+	Rows int64 `json:"rows,omitempty"`
+
+	// Outputs are the outputs reported by each replica, by rank.
+	// +optional
+	Outputs []PredictionOutput `json:"outputs,omitempty"`
+}
+
+// PredictionOutput is the output reported by the replica of a Predict or Evaluate job.
+type PredictionOutput struct {
+	// Rank of the replica.
+	Rank int32 `json:"rank"`
+
+	// URI of the predictions of the replica.
+	// +optional
+	URI string `json:"uri,omitempty"`
+
+	// Rows is the number of rows predicted by the replica.
+	// +optional
+	Rows *int64 `json:"rows,omitempty"`
+
+	// Metrics are the evaluation metrics of the replica, e.g. auc.
+	// +optional
+	Metrics map[string]string `json:"metrics,omitempty"`
+}
+
+// DataDownloadStatus counts the pods of a replica type by the state of their data download.
+type DataDownloadStatus struct {
+	// Downloading is the number of pods downloading their data.
+	// +optional
+	Downloading int32 `json:"downloading,omitempty"`
+
+	// Downloaded is the number of pods which downloaded their data.
+	// +optional
+	Downloaded int32 `json:"downloaded,omitempty"`
+
+	// Failed is the number of pods which failed to download their data.
+	// +optional
+	Failed int32 `json:"failed,omitempty"`
+}
+
+// ModelStatus is the model saved by a job.
+type ModelStatus struct {
+	// URI of the model.
+	URI string `json:"uri"`
+
+	// SizeBytes is the size of the model, if reported by the coordinator pod.
+	// +optional
+	SizeBytes *int64 `json:"sizeBytes,omitempty"`
+
+	// Format of the model, e.g. json, ubj or binary.
+	// +optional
+	Format string `json:"format,omitempty"`
+
+	// Metrics are the final evaluation metrics of the model, if reported by the
+	// coordinator pod, e.g. "validation-auc": "0.97".
+	// +optional
+	Metrics map[string]string `json:"metrics,omitempty"`
+}
+
+// CheckpointStatus is a checkpoint saved by a job.
+type CheckpointStatus struct {
+	// Path of the checkpoint in the containers.
+	Path string `json:"path"`
+
+	// ReportTime is when the controller found the checkpoint.
+	ReportTime metav1.Time `json:"reportTime"`
 }
 
 // +genclient
@@ -44,6 +423,7 @@ type XGBoostJobStatus struct {
 
 // XGBoostJob is the Schema for the xgboostjobs API
 // +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
 type XGBoostJob struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
@@ -61,6 +441,82 @@ type XGBoostJobList struct {
 	Items           []XGBoostJob `json:"items"`
 }
 
+// Framework is the training framework of an XGBoostJob.
+type Framework string
+
+const (
+	// FrameworkXGBoost runs distributed XGBoost, the workers connect to a Rabit tracker.
+	FrameworkXGBoost Framework = "XGBoost"
+
+	// FrameworkLightGBM runs distributed LightGBM, the workers connect to each other
+	// through the machine list mounted in every replica.
+	FrameworkLightGBM Framework = "LightGBM"
+)
+
+// JobType is what an XGBoostJob does.
+type JobType string
+
+const (
+	// JobTypeTrain trains a model, saved to the output of the job.
+	JobTypeTrain JobType = "Train"
+
+	// JobTypePredict predicts with the model of the job.
+	JobTypePredict JobType = "Predict"
+
+	// JobTypeEvaluate evaluates the model of the job.
+	JobTypeEvaluate JobType = "Evaluate"
+)
+
+// SuccessPolicy decides when an XGBoostJob succeeds. In a job without Master, Worker-0
+// takes the place of the Master.
+type SuccessPolicy string
+
+const (
+	// SuccessPolicyMasterCompleted marks the job succeeded once the Master completes.
+	SuccessPolicyMasterCompleted SuccessPolicy = "MasterCompleted"
+
+	// SuccessPolicyAllWorkersCompleted marks the job succeeded once all the Workers
+	// complete, or the Master if the job has no Worker.
+	SuccessPolicyAllWorkersCompleted SuccessPolicy = "AllWorkersCompleted"
+
+	// SuccessPolicyMasterAndAllWorkers marks the job succeeded once the Master and all
+	// the Workers complete.
+	SuccessPolicyMasterAndAllWorkers SuccessPolicy = "MasterAndAllWorkers"
+)
+
+// RestartScope decides which pods of an XGBoostJob are restarted on a retryable failure.
+type RestartScope string
+
+const (
+	// RestartScopePod restarts the failed pods only.
+	RestartScopePod RestartScope = "Pod"
+
+	// RestartScopeJob restarts all the Master and Worker pods together in a new attempt,
+	// so that a Rabit ring is bootstrapped again from scratch.
+	RestartScopeJob RestartScope = "Job"
+)
+
+// JobSuspended means the pods of the job are deleted until spec.suspend is unset. It is
+// a condition of an XGBoostJob in addition to the common ones.
+const JobSuspended commonv1.JobConditionType = "Suspended"
+
+// JobGangSchedulingUnavailable means the job asks for gang scheduling but the operator
+// cannot create its PodGroup, its pods are scheduled one by one.
+const JobGangSchedulingUnavailable commonv1.JobConditionType = "GangSchedulingUnavailable"
+
+// ShardingMode decides how the data of an XGBoostJob is split among its replicas.
+type ShardingMode string
+
+const (
+	// ShardingModeByFile gives every replica a share of the data files, round-robin by
+	// rank.
+	ShardingModeByFile ShardingMode = "ByFile"
+
+	// ShardingModeByRowRange gives every replica a contiguous range of the rows of all
+	// the data files.
+	ShardingModeByRowRange ShardingMode = "ByRowRange"
+)
+
 // XGBoostJobReplicaType is the type for XGBoostJobReplica.
 type XGBoostJobReplicaType commonv1.ReplicaType
 
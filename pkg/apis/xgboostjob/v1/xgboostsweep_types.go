@@ -0,0 +1,200 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	commonv1 "github.com/kubeflow/common/pkg/apis/common/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// XGBoostSweepSpec defines the desired state of XGBoostSweep
+type XGBoostSweepSpec struct {
+	// Template is the XGBoostJob every trial is created from, with the swept parameters
+	// set in its xgbParams.extra. It must be a Train job with an output, every trial
+	// saves its model under it.
+	Template XGBoostJobTemplateSpec `json:"template"`
+
+	// Algorithm decides how the values of the parameters of the trials are chosen, one
+	// of Grid and Random. Defaults to Grid.
+	// +optional
+	Algorithm SweepAlgorithm `json:"algorithm,omitempty"`
+
+	// Parameters are the XGBoost parameters swept.
+	Parameters []SweepParameter `json:"parameters"`
+
+	// Objective is the metric the best trial is chosen by.
+	Objective SweepObjective `json:"objective"`
+
+	// MaxTrials is the number of trials of the Random algorithm. With the Grid algorithm,
+	// it caps the number of combinations tried, all of them by default.
+	// +optional
+	MaxTrials *int32 `json:"maxTrials,omitempty"`
+
+	// MaxParallelTrials is the number of trials running at the same time. Defaults to 1.
+	// +optional
+	MaxParallelTrials *int32 `json:"maxParallelTrials,omitempty"`
+
+	// Seed of the Random algorithm, so that a sweep can be repeated.
+	// +optional
+	Seed *int64 `json:"seed,omitempty"`
+}
+
+// XGBoostJobTemplateSpec describes the XGBoostJobs created by a sweep.
+type XGBoostJobTemplateSpec struct {
+	// Metadata of the trials. Only the labels and the annotations are used.
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec of the trials.
+	Spec XGBoostJobSpec `json:"spec"`
+}
+
+// SweepParameter is a parameter swept, either among a list of values or, with the Random
+// algorithm, in a range.
+type SweepParameter struct {
+	// Name of the parameter, e.g. max_depth.
+	Name string `json:"name"`
+
+	// Values the parameter takes. Required by the Grid algorithm.
+	// +optional
+	Values []string `json:"values,omitempty"`
+
+	// Min is the lowest value drawn by the Random algorithm when the parameter has no
+	// values. Integers are drawn if both Min and Max are integers.
+	// +optional
+	Min string `json:"min,omitempty"`
+
+	// Max is the highest value drawn by the Random algorithm when the parameter has no
+	// values.
+	// +optional
+	Max string `json:"max,omitempty"`
+}
+
+// SweepObjective is the metric the best trial of a sweep is chosen by.
+type SweepObjective struct {
+	// MetricName is the name of the metric in status.model.metrics of the trials, e.g.
+	// validation-auc.
+	MetricName string `json:"metricName"`
+
+	// Goal is Maximize or Minimize. Defaults to Maximize.
+	// +optional
+	Goal ObjectiveGoal `json:"goal,omitempty"`
+}
+
+// XGBoostSweepStatus defines the observed state of XGBoostSweep
+type XGBoostSweepStatus struct {
+	// Conditions are the Created, Running, Succeeded or Failed conditions of the sweep.
+	// +optional
+	Conditions []commonv1.JobCondition `json:"conditions,omitempty"`
+
+	// StartTime is when the first trial was created.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is when the last trial finished.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// Trials are the trials created, in order.
+	// +optional
+	Trials []SweepTrial `json:"trials,omitempty"`
+
+	// BestTrial is the succeeded trial with the best objective metric.
+	// +optional
+	BestTrial *SweepTrial `json:"bestTrial,omitempty"`
+}
+
+// SweepTrial is an XGBoostJob created by a sweep.
+type SweepTrial struct {
+	// Name of the XGBoostJob of the trial.
+	Name string `json:"name"`
+
+	// Parameters are the values of the swept parameters of the trial.
+	// +optional
+	Parameters map[string]string `json:"parameters,omitempty"`
+
+	// Phase of the trial, one of Running, Succeeded and Failed.
+	Phase TrialPhase `json:"phase"`
+
+	// Metric is the objective metric reported by the succeeded trial.
+	// +optional
+	Metric string `json:"metric,omitempty"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// XGBoostSweep is the Schema for the xgboostsweeps API
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+type XGBoostSweep struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   XGBoostSweepSpec   `json:"spec,omitempty"`
+	Status XGBoostSweepStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// XGBoostSweepList contains a list of XGBoostSweep
+type XGBoostSweepList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []XGBoostSweep `json:"items"`
+}
+
+// SweepAlgorithm decides the values of the parameters of the trials of a sweep.
+type SweepAlgorithm string
+
+const (
+	// SweepAlgorithmGrid tries the combinations of the values of the parameters, in order,
+	// the first parameter changing the slowest.
+	SweepAlgorithmGrid SweepAlgorithm = "Grid"
+
+	// SweepAlgorithmRandom draws the value of every parameter of every trial at random.
+	SweepAlgorithmRandom SweepAlgorithm = "Random"
+)
+
+// ObjectiveGoal decides whether the best trial of a sweep has the highest or the lowest
+// metric.
+type ObjectiveGoal string
+
+const (
+	// ObjectiveGoalMaximize chooses the trial with the highest metric, e.g. auc.
+	ObjectiveGoalMaximize ObjectiveGoal = "Maximize"
+
+	// ObjectiveGoalMinimize chooses the trial with the lowest metric, e.g. logloss.
+	ObjectiveGoalMinimize ObjectiveGoal = "Minimize"
+)
+
+// TrialPhase is the phase of a trial of a sweep.
+type TrialPhase string
+
+const (
+	// TrialPhaseRunning is a trial whose XGBoostJob has not finished.
+	TrialPhaseRunning TrialPhase = "Running"
+
+	// TrialPhaseSucceeded is a trial whose XGBoostJob succeeded.
+	TrialPhaseSucceeded TrialPhase = "Succeeded"
+
+	// TrialPhaseFailed is a trial whose XGBoostJob failed or was deleted.
+	TrialPhaseFailed TrialPhase = "Failed"
+)
+
+func init() {
+	SchemeBuilder.Register(&XGBoostSweep{}, &XGBoostSweepList{})
+}
@@ -24,6 +24,303 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CheckpointSpec) DeepCopyInto(out *CheckpointSpec) {
+	*out = *in
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CheckpointSpec.
+func (in *CheckpointSpec) DeepCopy() *CheckpointSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CheckpointSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CheckpointStatus) DeepCopyInto(out *CheckpointStatus) {
+	*out = *in
+	in.ReportTime.DeepCopyInto(&out.ReportTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CheckpointStatus.
+func (in *CheckpointStatus) DeepCopy() *CheckpointStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CheckpointStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataDownloadSpec) DeepCopyInto(out *DataDownloadSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataDownloadSpec.
+func (in *DataDownloadSpec) DeepCopy() *DataDownloadSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DataDownloadSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataDownloadStatus) DeepCopyInto(out *DataDownloadStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataDownloadStatus.
+func (in *DataDownloadStatus) DeepCopy() *DataDownloadStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DataDownloadStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataSpec) DeepCopyInto(out *DataSpec) {
+	*out = *in
+	if in.Files != nil {
+		in, out := &in.Files, &out.Files
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Rows != nil {
+		in, out := &in.Rows, &out.Rows
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Download != nil {
+		in, out := &in.Download, &out.Download
+		*out = new(DataDownloadSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataSpec.
+func (in *DataSpec) DeepCopy() *DataSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DataSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelSource) DeepCopyInto(out *ModelSource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelSource.
+func (in *ModelSource) DeepCopy() *ModelSource {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelStatus) DeepCopyInto(out *ModelStatus) {
+	*out = *in
+	if in.SizeBytes != nil {
+		in, out := &in.SizeBytes, &out.SizeBytes
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelStatus.
+func (in *ModelStatus) DeepCopy() *ModelStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OutputSpec) DeepCopyInto(out *OutputSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OutputSpec.
+func (in *OutputSpec) DeepCopy() *OutputSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OutputSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PredictionOutput) DeepCopyInto(out *PredictionOutput) {
+	*out = *in
+	if in.Rows != nil {
+		in, out := &in.Rows, &out.Rows
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PredictionOutput.
+func (in *PredictionOutput) DeepCopy() *PredictionOutput {
+	if in == nil {
+		return nil
+	}
+	out := new(PredictionOutput)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PredictionStatus) DeepCopyInto(out *PredictionStatus) {
+	*out = *in
+	if in.Outputs != nil {
+		in, out := &in.Outputs, &out.Outputs
+		*out = make([]PredictionOutput, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PredictionStatus.
+func (in *PredictionStatus) DeepCopy() *PredictionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PredictionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabitTrackerSpec) DeepCopyInto(out *RabitTrackerSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabitTrackerSpec.
+func (in *RabitTrackerSpec) DeepCopy() *RabitTrackerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RabitTrackerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SweepObjective) DeepCopyInto(out *SweepObjective) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SweepObjective.
+func (in *SweepObjective) DeepCopy() *SweepObjective {
+	if in == nil {
+		return nil
+	}
+	out := new(SweepObjective)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SweepParameter) DeepCopyInto(out *SweepParameter) {
+	*out = *in
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SweepParameter.
+func (in *SweepParameter) DeepCopy() *SweepParameter {
+	if in == nil {
+		return nil
+	}
+	out := new(SweepParameter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SweepTrial) DeepCopyInto(out *SweepTrial) {
+	*out = *in
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SweepTrial.
+func (in *SweepTrial) DeepCopy() *SweepTrial {
+	if in == nil {
+		return nil
+	}
+	out := new(SweepTrial)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TrainingMetricsStatus) DeepCopyInto(out *TrainingMetricsStatus) {
+	*out = *in
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.ReportTime.DeepCopyInto(&out.ReportTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrainingMetricsStatus.
+func (in *TrainingMetricsStatus) DeepCopy() *TrainingMetricsStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TrainingMetricsStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *XGBoostJob) DeepCopyInto(out *XGBoostJob) {
 	*out = *in
@@ -102,6 +399,41 @@ func (in *XGBoostJobSpec) DeepCopyInto(out *XGBoostJobSpec) {
 			(*out)[key] = outVal
 		}
 	}
+	if in.XGBParams != nil {
+		in, out := &in.XGBParams, &out.XGBParams
+		*out = new(XGBoostParams)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RabitTracker != nil {
+		in, out := &in.RabitTracker, &out.RabitTracker
+		*out = new(RabitTrackerSpec)
+		**out = **in
+	}
+	if in.Checkpoint != nil {
+		in, out := &in.Checkpoint, &out.Checkpoint
+		*out = new(CheckpointSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ModelFrom != nil {
+		in, out := &in.ModelFrom, &out.ModelFrom
+		*out = new(ModelSource)
+		**out = **in
+	}
+	if in.Output != nil {
+		in, out := &in.Output, &out.Output
+		*out = new(OutputSpec)
+		**out = **in
+	}
+	if in.Data != nil {
+		in, out := &in.Data, &out.Data
+		*out = new(DataSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ProgressDeadlineSeconds != nil {
+		in, out := &in.ProgressDeadlineSeconds, &out.ProgressDeadlineSeconds
+		*out = new(int64)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new XGBoostJobSpec.
@@ -118,6 +450,48 @@ func (in *XGBoostJobSpec) DeepCopy() *XGBoostJobSpec {
 func (in *XGBoostJobStatus) DeepCopyInto(out *XGBoostJobStatus) {
 	*out = *in
 	in.JobStatus.DeepCopyInto(&out.JobStatus)
+	if in.RestartCounts != nil {
+		in, out := &in.RestartCounts, &out.RestartCounts
+		*out = make(map[commonv1.ReplicaType]int32, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.LastCheckpoint != nil {
+		in, out := &in.LastCheckpoint, &out.LastCheckpoint
+		*out = new(CheckpointStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Model != nil {
+		in, out := &in.Model, &out.Model
+		*out = new(ModelStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DataDownloads != nil {
+		in, out := &in.DataDownloads, &out.DataDownloads
+		*out = make(map[commonv1.ReplicaType]*DataDownloadStatus, len(*in))
+		for key, val := range *in {
+			var outVal *DataDownloadStatus
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = new(DataDownloadStatus)
+				**out = **in
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.Predictions != nil {
+		in, out := &in.Predictions, &out.Predictions
+		*out = new(PredictionStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TrainingMetrics != nil {
+		in, out := &in.TrainingMetrics, &out.TrainingMetrics
+		*out = new(TrainingMetricsStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new XGBoostJobStatus.
@@ -129,3 +503,227 @@ func (in *XGBoostJobStatus) DeepCopy() *XGBoostJobStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *XGBoostJobTemplateSpec) DeepCopyInto(out *XGBoostJobTemplateSpec) {
+	*out = *in
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new XGBoostJobTemplateSpec.
+func (in *XGBoostJobTemplateSpec) DeepCopy() *XGBoostJobTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(XGBoostJobTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *XGBoostParams) DeepCopyInto(out *XGBoostParams) {
+	*out = *in
+	if in.NumClass != nil {
+		in, out := &in.NumClass, &out.NumClass
+		*out = new(int32)
+		**out = **in
+	}
+	if in.NumRound != nil {
+		in, out := &in.NumRound, &out.NumRound
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxDepth != nil {
+		in, out := &in.MaxDepth, &out.MaxDepth
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Eta != nil {
+		in, out := &in.Eta, &out.Eta
+		*out = new(float64)
+		**out = **in
+	}
+	if in.Gamma != nil {
+		in, out := &in.Gamma, &out.Gamma
+		*out = new(float64)
+		**out = **in
+	}
+	if in.Subsample != nil {
+		in, out := &in.Subsample, &out.Subsample
+		*out = new(float64)
+		**out = **in
+	}
+	if in.ColsampleByTree != nil {
+		in, out := &in.ColsampleByTree, &out.ColsampleByTree
+		*out = new(float64)
+		**out = **in
+	}
+	if in.EvalMetric != nil {
+		in, out := &in.EvalMetric, &out.EvalMetric
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Seed != nil {
+		in, out := &in.Seed, &out.Seed
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Extra != nil {
+		in, out := &in.Extra, &out.Extra
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new XGBoostParams.
+func (in *XGBoostParams) DeepCopy() *XGBoostParams {
+	if in == nil {
+		return nil
+	}
+	out := new(XGBoostParams)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *XGBoostSweep) DeepCopyInto(out *XGBoostSweep) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new XGBoostSweep.
+func (in *XGBoostSweep) DeepCopy() *XGBoostSweep {
+	if in == nil {
+		return nil
+	}
+	out := new(XGBoostSweep)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *XGBoostSweep) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *XGBoostSweepList) DeepCopyInto(out *XGBoostSweepList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]XGBoostSweep, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new XGBoostSweepList.
+func (in *XGBoostSweepList) DeepCopy() *XGBoostSweepList {
+	if in == nil {
+		return nil
+	}
+	out := new(XGBoostSweepList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *XGBoostSweepList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *XGBoostSweepSpec) DeepCopyInto(out *XGBoostSweepSpec) {
+	*out = *in
+	in.Template.DeepCopyInto(&out.Template)
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make([]SweepParameter, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	out.Objective = in.Objective
+	if in.MaxTrials != nil {
+		in, out := &in.MaxTrials, &out.MaxTrials
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxParallelTrials != nil {
+		in, out := &in.MaxParallelTrials, &out.MaxParallelTrials
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Seed != nil {
+		in, out := &in.Seed, &out.Seed
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new XGBoostSweepSpec.
+func (in *XGBoostSweepSpec) DeepCopy() *XGBoostSweepSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(XGBoostSweepSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *XGBoostSweepStatus) DeepCopyInto(out *XGBoostSweepStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]commonv1.JobCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Trials != nil {
+		in, out := &in.Trials, &out.Trials
+		*out = make([]SweepTrial, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.BestTrial != nil {
+		in, out := &in.BestTrial, &out.BestTrial
+		*out = new(SweepTrial)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new XGBoostSweepStatus.
+func (in *XGBoostSweepStatus) DeepCopy() *XGBoostSweepStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(XGBoostSweepStatus)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,98 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"encoding/json"
+	"reflect"
+
+	commonv1 "github.com/kubeflow/common/pkg/apis/common/v1"
+	v1xgboost "github.com/kubeflow/xgboost-operator/pkg/apis/xgboostjob/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+// v1FieldsAnnotation keeps the fields of a v1 XGBoostJob that do not exist in v1alpha1,
+// so that they survive a v1alpha1 read-modify-write of the job.
+const v1FieldsAnnotation = "xgboostjob.kubeflow.org/v1-fields"
+
+// v1Fields is the content of the v1FieldsAnnotation, the spec and status of the v1 job
+// without their v1alpha1 fields.
+type v1Fields struct {
+	Spec   *v1xgboost.XGBoostJobSpec   `json:"spec,omitempty"`
+	Status *v1xgboost.XGBoostJobStatus `json:"status,omitempty"`
+}
+
+var _ conversion.Convertible = &XGBoostJob{}
+
+// ConvertTo converts this XGBoostJob to the Hub version (v1).
+// Fields that only exist in v1 are restored from the v1FieldsAnnotation.
+func (src *XGBoostJob) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1xgboost.XGBoostJob)
+
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	if data, ok := dst.Annotations[v1FieldsAnnotation]; ok {
+		fields := &v1Fields{}
+		if err := json.Unmarshal([]byte(data), fields); err != nil {
+			return err
+		}
+		if fields.Spec != nil {
+			dst.Spec = *fields.Spec
+		}
+		if fields.Status != nil {
+			dst.Status = *fields.Status
+		}
+		delete(dst.Annotations, v1FieldsAnnotation)
+		if len(dst.Annotations) == 0 {
+			dst.Annotations = nil
+		}
+	}
+	dst.Spec.RunPolicy = *src.Spec.RunPolicy.DeepCopy()
+	dst.Spec.XGBReplicaSpecs = src.Spec.DeepCopy().XGBReplicaSpecs
+	dst.Status.JobStatus = *src.Status.JobStatus.DeepCopy()
+	return nil
+}
+
+// ConvertFrom converts from the Hub version (v1) to this version.
+// Fields that only exist in v1 are kept in the v1FieldsAnnotation.
+func (dst *XGBoostJob) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1xgboost.XGBoostJob)
+
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	dst.Spec.RunPolicy = *src.Spec.RunPolicy.DeepCopy()
+	dst.Spec.XGBReplicaSpecs = src.Spec.DeepCopy().XGBReplicaSpecs
+	dst.Status.JobStatus = *src.Status.JobStatus.DeepCopy()
+
+	fields := &v1Fields{}
+	spec := src.Spec.DeepCopy()
+	spec.RunPolicy, spec.XGBReplicaSpecs = commonv1.RunPolicy{}, nil
+	if !reflect.DeepEqual(*spec, v1xgboost.XGBoostJobSpec{}) {
+		fields.Spec = spec
+	}
+	status := src.Status.DeepCopy()
+	status.JobStatus = commonv1.JobStatus{}
+	if !reflect.DeepEqual(*status, v1xgboost.XGBoostJobStatus{}) {
+		fields.Status = status
+	}
+	if fields.Spec == nil && fields.Status == nil {
+		return nil
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	if dst.Annotations == nil {
+		dst.Annotations = map[string]string{}
+	}
+	dst.Annotations[v1FieldsAnnotation] = string(data)
+	return nil
+}
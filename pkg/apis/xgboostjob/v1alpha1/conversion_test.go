@@ -0,0 +1,132 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+	"testing"
+
+	commonv1 "github.com/kubeflow/common/pkg/apis/common/v1"
+	v1xgboost "github.com/kubeflow/xgboost-operator/pkg/apis/xgboostjob/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestConversionRoundTrip(t *testing.T) {
+	replicas := int32(2)
+	cleanPodPolicy := commonv1.CleanPodPolicyAll
+	job := &XGBoostJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-xgboostjob",
+			Namespace: metav1.NamespaceDefault,
+			Labels:    map[string]string{"app": "iris"},
+		},
+		Spec: XGBoostJobSpec{
+			RunPolicy: commonv1.RunPolicy{CleanPodPolicy: &cleanPodPolicy},
+			XGBReplicaSpecs: map[commonv1.ReplicaType]*commonv1.ReplicaSpec{
+				"Worker": {
+					Replicas:      &replicas,
+					RestartPolicy: commonv1.RestartPolicyExitCode,
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{{Name: "xgboostjob", Image: "test-image"}},
+						},
+					},
+				},
+			},
+		},
+		Status: XGBoostJobStatus{
+			JobStatus: commonv1.JobStatus{
+				Conditions: []commonv1.JobCondition{{Type: commonv1.JobRunning, Status: corev1.ConditionTrue}},
+			},
+		},
+	}
+
+	hub := &v1xgboost.XGBoostJob{}
+	if err := job.ConvertTo(hub); err != nil {
+		t.Fatalf("Failed to convert to v1: %v", err)
+	}
+	if *hub.Spec.XGBReplicaSpecs["Worker"].Replicas != replicas {
+		t.Errorf("Got replicas %d in v1. Expected %d", *hub.Spec.XGBReplicaSpecs["Worker"].Replicas, replicas)
+	}
+
+	converted := &XGBoostJob{}
+	if err := converted.ConvertFrom(hub); err != nil {
+		t.Fatalf("Failed to convert from v1: %v", err)
+	}
+	if !reflect.DeepEqual(job, converted) {
+		t.Errorf("Got %+v after round trip. Expected %+v", converted, job)
+	}
+}
+
+func TestConversionRoundTripFromHub(t *testing.T) {
+	replicas := int32(2)
+	numRound := int32(100)
+	progressDeadlineSeconds := int64(600)
+	reportTime := metav1.Unix(1600000000, 0)
+	hub := &v1xgboost.XGBoostJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-xgboostjob",
+			Namespace: metav1.NamespaceDefault,
+		},
+		Spec: v1xgboost.XGBoostJobSpec{
+			XGBReplicaSpecs: map[commonv1.ReplicaType]*commonv1.ReplicaSpec{
+				"Worker": {
+					Replicas: &replicas,
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{{Name: "xgboostjob", Image: "test-image"}},
+						},
+					},
+				},
+			},
+			Framework:               v1xgboost.FrameworkLightGBM,
+			JobType:                 v1xgboost.JobTypePredict,
+			ModelFrom:               &v1xgboost.ModelSource{XGBoostJobRef: "train"},
+			SuccessPolicy:           v1xgboost.SuccessPolicyMasterAndAllWorkers,
+			RestartScope:            v1xgboost.RestartScopeJob,
+			XGBParams:               &v1xgboost.XGBoostParams{Objective: "binary:logistic", NumRound: &numRound},
+			Checkpoint:              &v1xgboost.CheckpointSpec{ClaimName: "checkpoints"},
+			Output:                  &v1xgboost.OutputSpec{ClaimName: "models"},
+			Data:                    &v1xgboost.DataSpec{URI: "gs://bucket/data"},
+			ProgressDeadlineSeconds: &progressDeadlineSeconds,
+			Suspend:                 true,
+		},
+		Status: v1xgboost.XGBoostJobStatus{
+			JobStatus: commonv1.JobStatus{
+				Conditions: []commonv1.JobCondition{{Type: commonv1.JobRunning, Status: corev1.ConditionTrue}},
+			},
+			RestartCounts:  map[commonv1.ReplicaType]int32{"Worker": 1},
+			Attempt:        1,
+			LastCheckpoint: &v1xgboost.CheckpointStatus{Path: "/checkpoints/10", ReportTime: reportTime},
+			InputModel:     "gs://bucket/model",
+			Predictions:    &v1xgboost.PredictionStatus{Rows: 10},
+		},
+	}
+
+	job := &XGBoostJob{}
+	if err := job.ConvertFrom(hub); err != nil {
+		t.Fatalf("Failed to convert from v1: %v", err)
+	}
+	// A v1alpha1 client updates the job without knowing the v1 fields.
+	job.Labels = map[string]string{"app": "iris"}
+
+	converted := &v1xgboost.XGBoostJob{}
+	if err := job.ConvertTo(converted); err != nil {
+		t.Fatalf("Failed to convert to v1: %v", err)
+	}
+	hub.Labels = job.Labels
+	if !reflect.DeepEqual(hub, converted) {
+		t.Errorf("Got %+v after round trip. Expected %+v", converted, hub)
+	}
+}
@@ -0,0 +1,20 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package v1alpha1 contains API Schema definitions for the xgboostjob v1alpha1 API group.
+// It is served for existing manifests only, objects are stored as v1.
+// +k8s:openapi-gen=true
+// +k8s:deepcopy-gen=package,register
+// +k8s:conversion-gen=github.com/kubeflow/xgboost-operator/pkg/apis/xgboostjob
+// +k8s:defaulter-gen=TypeMeta
+// +groupName=xgboostjob.kubeflow.org
+package v1alpha1
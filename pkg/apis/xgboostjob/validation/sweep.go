@@ -0,0 +1,152 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"strconv"
+
+	v1xgboost "github.com/kubeflow/xgboost-operator/pkg/apis/xgboostjob/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// supportedSweepAlgorithms are the ways the controller chooses the parameters of the trials.
+var supportedSweepAlgorithms = []string{string(v1xgboost.SweepAlgorithmGrid), string(v1xgboost.SweepAlgorithmRandom)}
+
+// supportedObjectiveGoals are the ways the controller chooses the best trial.
+var supportedObjectiveGoals = []string{string(v1xgboost.ObjectiveGoalMaximize), string(v1xgboost.ObjectiveGoalMinimize)}
+
+// ValidateV1XGBoostSweep validates an XGBoostSweep.
+func ValidateV1XGBoostSweep(sweep *v1xgboost.XGBoostSweep) field.ErrorList {
+	allErrs := field.ErrorList{}
+	spec := &sweep.Spec
+	fldPath := field.NewPath("spec")
+
+	// The trials are defaulted when they are created, so is the template before it is validated.
+	trial := &v1xgboost.XGBoostJob{Spec: *spec.Template.Spec.DeepCopy()}
+	v1xgboost.SetDefaults_XGBoostJob(trial)
+	templatePath := fldPath.Child("template", "spec")
+	allErrs = append(allErrs, ValidateV1XGBoostJobSpec(&trial.Spec, templatePath)...)
+	if trial.Spec.JobType != v1xgboost.JobTypeTrain {
+		allErrs = append(allErrs, field.NotSupported(templatePath.Child("jobType"), trial.Spec.JobType,
+			[]string{string(v1xgboost.JobTypeTrain)}))
+	}
+	if trial.Spec.Output == nil {
+		allErrs = append(allErrs, field.Required(templatePath.Child("output"), "the trials report their metrics with their model"))
+	}
+	if trial.Spec.Checkpoint != nil {
+		allErrs = append(allErrs, field.Forbidden(templatePath.Child("checkpoint"), "the trials would resume from each other's checkpoints"))
+	}
+
+	if spec.Algorithm != "" && !contains(supportedSweepAlgorithms, string(spec.Algorithm)) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("algorithm"), spec.Algorithm, supportedSweepAlgorithms))
+	}
+	if len(spec.Parameters) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("parameters"), "at least one parameter is required"))
+	}
+	names := map[string]bool{}
+	for i := range spec.Parameters {
+		param := &spec.Parameters[i]
+		paramPath := fldPath.Child("parameters").Index(i)
+		if param.Name == "" {
+			allErrs = append(allErrs, field.Required(paramPath.Child("name"), ""))
+		} else if names[param.Name] {
+			allErrs = append(allErrs, field.Duplicate(paramPath.Child("name"), param.Name))
+		}
+		names[param.Name] = true
+		if trial.Spec.XGBParams != nil && isTypedXGBParamSet(trial.Spec.XGBParams, param.Name) {
+			allErrs = append(allErrs, field.Invalid(paramPath.Child("name"), param.Name,
+				"must not be set as a typed parameter in the xgbParams of the template, it would take precedence"))
+		}
+		allErrs = append(allErrs, validateSweepParameter(spec.Algorithm, param, paramPath)...)
+	}
+
+	if spec.Objective.MetricName == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("objective", "metricName"), ""))
+	}
+	if spec.Objective.Goal != "" && !contains(supportedObjectiveGoals, string(spec.Objective.Goal)) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("objective", "goal"), spec.Objective.Goal, supportedObjectiveGoals))
+	}
+	if spec.MaxTrials == nil && spec.Algorithm == v1xgboost.SweepAlgorithmRandom {
+		allErrs = append(allErrs, field.Required(fldPath.Child("maxTrials"), "required by the Random algorithm"))
+	}
+	if spec.MaxTrials != nil && *spec.MaxTrials < 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("maxTrials"), *spec.MaxTrials, "must be greater than 0"))
+	}
+	if spec.MaxParallelTrials != nil && *spec.MaxParallelTrials < 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("maxParallelTrials"), *spec.MaxParallelTrials, "must be greater than 0"))
+	}
+	return allErrs
+}
+
+// validateSweepParameter checks that the values of a parameter can be chosen by the
+// algorithm of the sweep.
+func validateSweepParameter(algorithm v1xgboost.SweepAlgorithm, param *v1xgboost.SweepParameter, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	hasRange := param.Min != "" || param.Max != ""
+	switch {
+	case len(param.Values) > 0 && hasRange:
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("min"), param.Min, "must not be set together with values"))
+	case len(param.Values) > 0:
+		return allErrs
+	case algorithm != v1xgboost.SweepAlgorithmRandom:
+		allErrs = append(allErrs, field.Required(fldPath.Child("values"), "required by the Grid algorithm"))
+	case !hasRange:
+		allErrs = append(allErrs, field.Required(fldPath.Child("values"), "one of values and min and max is required"))
+	default:
+		min, minErr := strconv.ParseFloat(param.Min, 64)
+		if minErr != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("min"), param.Min, "must be a number"))
+		}
+		max, maxErr := strconv.ParseFloat(param.Max, 64)
+		if maxErr != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("max"), param.Max, "must be a number"))
+		}
+		if minErr == nil && maxErr == nil && min > max {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("max"), param.Max, "must be greater than or equal to min"))
+		}
+	}
+	return allErrs
+}
+
+// isTypedXGBParamSet returns true if the parameter of the given XGBoost name is set as a
+// typed field of params.
+func isTypedXGBParamSet(params *v1xgboost.XGBoostParams, name string) bool {
+	switch name {
+	case "objective":
+		return params.Objective != ""
+	case "num_class":
+		return params.NumClass != nil
+	case "num_round":
+		return params.NumRound != nil
+	case "max_depth":
+		return params.MaxDepth != nil
+	case "eta":
+		return params.Eta != nil
+	case "gamma":
+		return params.Gamma != nil
+	case "subsample":
+		return params.Subsample != nil
+	case "colsample_bytree":
+		return params.ColsampleByTree != nil
+	case "eval_metric":
+		return len(params.EvalMetric) > 0
+	case "tree_method":
+		return params.TreeMethod != ""
+	case "seed":
+		return params.Seed != nil
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"testing"
+
+	v1xgboost "github.com/kubeflow/xgboost-operator/pkg/apis/xgboostjob/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newSweep(algorithm v1xgboost.SweepAlgorithm, params ...v1xgboost.SweepParameter) *v1xgboost.XGBoostSweep {
+	template := newJob(1, 2)
+	template.Spec.Output = &v1xgboost.OutputSpec{URI: "oss://models/iris"}
+	return &v1xgboost.XGBoostSweep{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-xgboostsweep", Namespace: metav1.NamespaceDefault},
+		Spec: v1xgboost.XGBoostSweepSpec{
+			Template:   v1xgboost.XGBoostJobTemplateSpec{Spec: template.Spec},
+			Algorithm:  algorithm,
+			Parameters: params,
+			Objective:  v1xgboost.SweepObjective{MetricName: "validation-auc"},
+		},
+	}
+}
+
+func TestValidateV1XGBoostSweep(t *testing.T) {
+	maxTrials := int32(10)
+	maxDepth := v1xgboost.SweepParameter{Name: "max_depth", Values: []string{"3", "6"}}
+	eta := v1xgboost.SweepParameter{Name: "eta", Min: "0.01", Max: "0.3"}
+
+	random := newSweep(v1xgboost.SweepAlgorithmRandom, maxDepth, eta)
+	random.Spec.MaxTrials = &maxTrials
+
+	randomWithoutMaxTrials := newSweep(v1xgboost.SweepAlgorithmRandom, maxDepth)
+
+	invalidRange := newSweep(v1xgboost.SweepAlgorithmRandom, v1xgboost.SweepParameter{Name: "eta", Min: "0.3", Max: "low"},
+		v1xgboost.SweepParameter{Name: "gamma", Min: "1", Max: "0"})
+	invalidRange.Spec.MaxTrials = &maxTrials
+
+	typedParam := newSweep(v1xgboost.SweepAlgorithmGrid, maxDepth)
+	depth := int32(4)
+	typedParam.Spec.Template.Spec.XGBParams = &v1xgboost.XGBoostParams{MaxDepth: &depth}
+
+	checkpointWithoutOutput := newSweep(v1xgboost.SweepAlgorithmGrid, maxDepth)
+	checkpointWithoutOutput.Spec.Template.Spec.Output = nil
+	checkpointWithoutOutput.Spec.Template.Spec.Checkpoint = &v1xgboost.CheckpointSpec{ClaimName: "checkpoints"}
+
+	predict := newSweep(v1xgboost.SweepAlgorithmGrid, maxDepth)
+	predict.Spec.Template.Spec.JobType = v1xgboost.JobTypePredict
+	predict.Spec.Template.Spec.ModelFrom = &v1xgboost.ModelSource{URI: "oss://models/iris.json"}
+
+	noObjective := newSweep("Bayesian", maxDepth, maxDepth)
+	noObjective.Spec.Objective = v1xgboost.SweepObjective{Goal: "Best"}
+
+	type tc struct {
+		sweep        *v1xgboost.XGBoostSweep
+		expectedErrs int
+	}
+	testCase := []tc{
+		tc{sweep: newSweep("", maxDepth), expectedErrs: 0},
+		tc{sweep: random, expectedErrs: 0},
+		tc{sweep: newSweep(v1xgboost.SweepAlgorithmGrid), expectedErrs: 1},
+		tc{sweep: newSweep(v1xgboost.SweepAlgorithmGrid, eta), expectedErrs: 1},
+		tc{sweep: randomWithoutMaxTrials, expectedErrs: 1},
+		tc{sweep: invalidRange, expectedErrs: 2},
+		tc{sweep: typedParam, expectedErrs: 1},
+		tc{sweep: checkpointWithoutOutput, expectedErrs: 2},
+		// A Predict job cannot have an output either.
+		tc{sweep: predict, expectedErrs: 2},
+		tc{sweep: noObjective, expectedErrs: 4},
+	}
+	for i, c := range testCase {
+		errs := ValidateV1XGBoostSweep(c.sweep)
+		if len(errs) != c.expectedErrs {
+			t.Errorf("Case %d: got %d errors %v. Expected %d", i, len(errs), errs, c.expectedErrs)
+		}
+	}
+}
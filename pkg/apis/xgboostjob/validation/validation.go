@@ -0,0 +1,509 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package validation checks that an XGBoostJob or an XGBoostSweep can be run by the controller.
+package validation
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	commonv1 "github.com/kubeflow/common/pkg/apis/common/v1"
+	v1xgboost "github.com/kubeflow/xgboost-operator/pkg/apis/xgboostjob/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// ValidateV1XGBoostJob validates a new XGBoostJob.
+func ValidateV1XGBoostJob(job *v1xgboost.XGBoostJob) field.ErrorList {
+	return ValidateV1XGBoostJobSpec(&job.Spec, field.NewPath("spec"))
+}
+
+// ValidateV1XGBoostJobUpdate validates an update of an XGBoostJob. Besides the
+// checks on a new job, the topology of a started job must stay unchanged since
+// the running replicas already got their ranks and addresses.
+func ValidateV1XGBoostJobUpdate(newJob, oldJob *v1xgboost.XGBoostJob) field.ErrorList {
+	allErrs := ValidateV1XGBoostJob(newJob)
+	if !isStarted(oldJob) {
+		return allErrs
+	}
+
+	if oldJob.Spec.Framework != newJob.Spec.Framework {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec").Child("framework"),
+			"field is immutable after the job started"))
+	}
+	if oldJob.Spec.JobType != newJob.Spec.JobType {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec").Child("jobType"),
+			"field is immutable after the job started"))
+	}
+	// The running replicas already got their shard of the data.
+	if !apiequality.Semantic.DeepEqual(oldJob.Spec.Data, newJob.Spec.Data) {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec").Child("data"),
+			"field is immutable after the job started"))
+	}
+
+	specsPath := field.NewPath("spec").Child("xgbReplicaSpecs")
+	for rtype, oldSpec := range oldJob.Spec.XGBReplicaSpecs {
+		newSpec, ok := newJob.Spec.XGBReplicaSpecs[rtype]
+		if !ok {
+			allErrs = append(allErrs, field.Forbidden(specsPath.Key(string(rtype)),
+				"replica type cannot be removed after the job started"))
+			continue
+		}
+		if oldSpec == nil || newSpec == nil {
+			continue
+		}
+		rtypePath := specsPath.Key(string(rtype))
+		if !apiequality.Semantic.DeepEqual(oldSpec.Replicas, newSpec.Replicas) {
+			allErrs = append(allErrs, field.Forbidden(rtypePath.Child("replicas"),
+				"field is immutable after the job started"))
+		}
+		if getPort(oldSpec) != getPort(newSpec) {
+			allErrs = append(allErrs, field.Forbidden(rtypePath.Child("template", "spec", "containers"),
+				fmt.Sprintf("port %s is immutable after the job started", v1xgboost.DefaultContainerPortName)))
+		}
+	}
+	for rtype := range newJob.Spec.XGBReplicaSpecs {
+		if _, ok := oldJob.Spec.XGBReplicaSpecs[rtype]; !ok {
+			allErrs = append(allErrs, field.Forbidden(specsPath.Key(string(rtype)),
+				"replica type cannot be added after the job started"))
+		}
+	}
+	return allErrs
+}
+
+// ValidateV1XGBoostJobSpec validates the spec of an XGBoostJob.
+func ValidateV1XGBoostJobSpec(spec *v1xgboost.XGBoostJobSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	specsPath := fldPath.Child("xgbReplicaSpecs")
+
+	if len(spec.XGBReplicaSpecs) == 0 {
+		allErrs = append(allErrs, field.Required(specsPath, "a Master replica or at least one Worker replica is required"))
+		return allErrs
+	}
+	allErrs = append(allErrs, validateTopology(spec.XGBReplicaSpecs, specsPath)...)
+
+	for rtype, replicaSpec := range spec.XGBReplicaSpecs {
+		allErrs = append(allErrs, validateReplicaSpec(rtype, replicaSpec, specsPath.Key(string(rtype)))...)
+	}
+	if spec.XGBParams != nil {
+		allErrs = append(allErrs, validateXGBParams(spec.XGBParams, fldPath.Child("xgbParams"))...)
+	}
+	allErrs = append(allErrs, validateFramework(spec, fldPath)...)
+	if spec.Checkpoint != nil {
+		allErrs = append(allErrs, validateCheckpoint(spec, fldPath.Child("checkpoint"))...)
+	}
+	if spec.Output != nil {
+		allErrs = append(allErrs, validateOutput(spec.Output, fldPath.Child("output"))...)
+	}
+	if spec.Data != nil {
+		allErrs = append(allErrs, validateData(spec, fldPath.Child("data"))...)
+	}
+	allErrs = append(allErrs, validateJobType(spec, fldPath)...)
+	if spec.SuccessPolicy != "" && !contains(supportedSuccessPolicies, string(spec.SuccessPolicy)) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("successPolicy"), spec.SuccessPolicy, supportedSuccessPolicies))
+	}
+	if spec.RestartScope != "" && !contains(supportedRestartScopes, string(spec.RestartScope)) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("restartScope"), spec.RestartScope, supportedRestartScopes))
+	}
+	if spec.ProgressDeadlineSeconds != nil && *spec.ProgressDeadlineSeconds < 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("progressDeadlineSeconds"), *spec.ProgressDeadlineSeconds, "must be greater than 0"))
+	}
+	return allErrs
+}
+
+// supportedRestartScopes are the restart scopes applied by the controller.
+var supportedRestartScopes = []string{string(v1xgboost.RestartScopePod), string(v1xgboost.RestartScopeJob)}
+
+// supportedSuccessPolicies are the success policies evaluated by the controller.
+var supportedSuccessPolicies = []string{
+	string(v1xgboost.SuccessPolicyMasterCompleted),
+	string(v1xgboost.SuccessPolicyAllWorkersCompleted),
+	string(v1xgboost.SuccessPolicyMasterAndAllWorkers),
+}
+
+// validateTopology checks that the job has a coordinator. It is Master-0, or Worker-0
+// when the job has no Master.
+func validateTopology(specs map[commonv1.ReplicaType]*commonv1.ReplicaSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if _, ok := specs[commonv1.ReplicaType(v1xgboost.XGBoostReplicaTypeMaster)]; ok {
+		return allErrs
+	}
+
+	workerType := commonv1.ReplicaType(v1xgboost.XGBoostReplicaTypeWorker)
+	worker, ok := specs[workerType]
+	if !ok {
+		allErrs = append(allErrs, field.Required(fldPath.Key(string(v1xgboost.XGBoostReplicaTypeMaster)),
+			"a Master replica is required, unless the job has Worker replicas, then Worker-0 coordinates the job"))
+		return allErrs
+	}
+	if worker != nil && worker.Replicas != nil && *worker.Replicas < 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Key(string(workerType)).Child("replicas"), *worker.Replicas,
+			"must be greater than 0 in a job without Master, since Worker-0 coordinates the job"))
+	}
+	return allErrs
+}
+
+// validateCheckpoint checks that the checkpoint volume is set once and is found in every
+// replica template if it is one of their volumes.
+func validateCheckpoint(spec *v1xgboost.XGBoostJobSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	checkpoint := spec.Checkpoint
+
+	switch {
+	case checkpoint.ClaimName == "" && checkpoint.VolumeName == "":
+		allErrs = append(allErrs, field.Required(fldPath.Child("claimName"), "one of claimName and volumeName is required"))
+	case checkpoint.ClaimName != "" && checkpoint.VolumeName != "":
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("volumeName"), checkpoint.VolumeName, "must not be set together with claimName"))
+	case checkpoint.VolumeName != "":
+		for rtype, replicaSpec := range spec.XGBReplicaSpecs {
+			if replicaSpec != nil && !hasVolume(replicaSpec.Template.Spec, checkpoint.VolumeName) {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("volumeName"), checkpoint.VolumeName,
+					fmt.Sprintf("must be a volume of the %s template", rtype)))
+			}
+		}
+	}
+	if checkpoint.Path != "" && !path.IsAbs(checkpoint.Path) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("path"), checkpoint.Path, "must be an absolute path"))
+	}
+	if checkpoint.Interval != nil && *checkpoint.Interval < 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("interval"), *checkpoint.Interval, "must be greater than 0"))
+	}
+	return allErrs
+}
+
+// validateOutput checks that the model is saved either on a claim or to an object store.
+func validateOutput(output *v1xgboost.OutputSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	switch {
+	case output.ClaimName == "" && output.URI == "":
+		allErrs = append(allErrs, field.Required(fldPath.Child("claimName"), "one of claimName and uri is required"))
+	case output.ClaimName != "" && output.URI != "":
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("uri"), output.URI, "must not be set together with claimName"))
+	case output.URI != "" && !isAbsoluteURI(output.URI):
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("uri"), output.URI, "must be an absolute URI such as oss://bucket/model.json"))
+	}
+	if output.Path != "" {
+		if output.ClaimName == "" {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("path"), output.Path, "must only be set together with claimName"))
+		} else if !path.IsAbs(output.Path) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("path"), output.Path, "must be an absolute path"))
+		}
+	}
+	if output.SubPath != "" {
+		if output.ClaimName == "" {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("subPath"), output.SubPath, "must only be set together with claimName"))
+		} else if path.IsAbs(output.SubPath) || strings.HasPrefix(path.Clean(output.SubPath), "..") {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("subPath"), output.SubPath, "must be a relative path within the claim"))
+		}
+	}
+	return allErrs
+}
+
+// supportedJobTypes are the job types run by the controller.
+var supportedJobTypes = []string{string(v1xgboost.JobTypeTrain), string(v1xgboost.JobTypePredict), string(v1xgboost.JobTypeEvaluate)}
+
+// validateJobType checks that Predict and Evaluate jobs have a model, either a URI or the
+// model of another job, and only Train jobs save one.
+func validateJobType(spec *v1xgboost.XGBoostJobSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if spec.JobType != "" && !contains(supportedJobTypes, string(spec.JobType)) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("jobType"), spec.JobType, supportedJobTypes))
+	}
+
+	isTrain := spec.JobType == "" || spec.JobType == v1xgboost.JobTypeTrain
+	if spec.ModelFrom == nil {
+		if !isTrain {
+			allErrs = append(allErrs, field.Required(fldPath.Child("modelFrom"), fmt.Sprintf("required by %s jobs", spec.JobType)))
+		}
+	} else {
+		modelFrom := spec.ModelFrom
+		switch {
+		case modelFrom.URI == "" && modelFrom.XGBoostJobRef == "":
+			allErrs = append(allErrs, field.Required(fldPath.Child("modelFrom", "uri"), "one of uri and xgboostJobRef is required"))
+		case modelFrom.URI != "" && modelFrom.XGBoostJobRef != "":
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("modelFrom", "xgboostJobRef"), modelFrom.XGBoostJobRef,
+				"must not be set together with uri"))
+		case modelFrom.URI != "" && !isAbsoluteURI(modelFrom.URI):
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("modelFrom", "uri"), modelFrom.URI,
+				"must be an absolute URI such as oss://bucket/model.json or pvc://claim/model.json"))
+		}
+	}
+	if spec.Output != nil && !isTrain {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("output"), fmt.Sprintf("%s jobs do not save a model", spec.JobType)))
+	}
+	return allErrs
+}
+
+// supportedShardingModes are the ways the controller splits the data among the replicas.
+var supportedShardingModes = []string{string(v1xgboost.ShardingModeByFile), string(v1xgboost.ShardingModeByRowRange)}
+
+// validateData checks the location of the data, and that it can be split so that every
+// replica gets a share of it.
+func validateData(spec *v1xgboost.XGBoostJobSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	data := spec.Data
+
+	switch {
+	case data.ClaimName == "" && data.URI == "":
+		allErrs = append(allErrs, field.Required(fldPath.Child("claimName"), "one of claimName and uri is required"))
+	case data.ClaimName != "" && data.URI != "":
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("uri"), data.URI, "must not be set together with claimName"))
+	case data.URI != "" && !isAbsoluteURI(data.URI):
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("uri"), data.URI, "must be an absolute URI such as oss://bucket/iris"))
+	}
+	if data.Path != "" {
+		if data.ClaimName == "" {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("path"), data.Path, "must only be set together with claimName"))
+		} else if !path.IsAbs(data.Path) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("path"), data.Path, "must be an absolute path"))
+		}
+	}
+	if data.Download != nil {
+		downloadPath := fldPath.Child("download")
+		if data.URI == "" {
+			allErrs = append(allErrs, field.Invalid(downloadPath, "", "must only be set together with uri"))
+		}
+		if data.Download.Image == "" {
+			allErrs = append(allErrs, field.Required(downloadPath.Child("image"), ""))
+		}
+		if data.Download.Destination != "" && !path.IsAbs(data.Download.Destination) {
+			allErrs = append(allErrs, field.Invalid(downloadPath.Child("destination"), data.Download.Destination, "must be an absolute path"))
+		}
+	}
+	for i, file := range data.Files {
+		if file == "" || path.IsAbs(file) || strings.HasPrefix(path.Clean(file), "..") {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("files").Index(i), file, "must be a relative path inside the data"))
+		}
+	}
+
+	replicas := getTotalReplicas(spec.XGBReplicaSpecs)
+	switch data.Sharding {
+	case "", v1xgboost.ShardingModeByFile:
+		// Without files, every replica gets the whole claim or uri.
+		if len(data.Files) > 0 && int32(len(data.Files)) < replicas {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("files"), len(data.Files),
+				fmt.Sprintf("must have a file for each of the %d replicas", replicas)))
+		}
+		if data.Rows != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("rows"), *data.Rows, "must only be set with the ByRowRange sharding"))
+		}
+	case v1xgboost.ShardingModeByRowRange:
+		if data.Rows == nil {
+			allErrs = append(allErrs, field.Required(fldPath.Child("rows"), "required with the ByRowRange sharding"))
+		} else if *data.Rows < int64(replicas) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("rows"), *data.Rows,
+				fmt.Sprintf("must have a row for each of the %d replicas", replicas)))
+		}
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("sharding"), data.Sharding, supportedShardingModes))
+	}
+	return allErrs
+}
+
+// supportedFrameworks are the frameworks run by the controller.
+var supportedFrameworks = []string{string(v1xgboost.FrameworkXGBoost), string(v1xgboost.FrameworkLightGBM)}
+
+func validateFramework(spec *v1xgboost.XGBoostJobSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if spec.Framework == "" {
+		return allErrs
+	}
+	if !contains(supportedFrameworks, string(spec.Framework)) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("framework"), spec.Framework, supportedFrameworks))
+		return allErrs
+	}
+
+	// LightGBM listens on the same local_listen_port on every machine.
+	if spec.Framework == v1xgboost.FrameworkLightGBM {
+		port := int32(-1)
+		for rtype, replicaSpec := range spec.XGBReplicaSpecs {
+			if replicaSpec == nil {
+				continue
+			}
+			p := getPort(replicaSpec)
+			if p < 0 {
+				continue
+			}
+			if port >= 0 && p != port {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("xgbReplicaSpecs").Key(string(rtype)), p,
+					fmt.Sprintf("every replica of a LightGBM job must use the same %s port", v1xgboost.DefaultContainerPortName)))
+			}
+			port = p
+		}
+	}
+	return allErrs
+}
+
+// supportedTreeMethods are the values of the XGBoost tree_method parameter.
+var supportedTreeMethods = []string{"auto", "exact", "approx", "hist", "gpu_hist"}
+
+func validateXGBParams(params *v1xgboost.XGBoostParams, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if params.NumClass != nil && *params.NumClass < 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("numClass"), *params.NumClass, "must be greater than 0"))
+	}
+	if params.NumRound != nil && *params.NumRound < 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("numRound"), *params.NumRound, "must be greater than 0"))
+	}
+	if params.MaxDepth != nil && *params.MaxDepth < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("maxDepth"), *params.MaxDepth, "must be greater than or equal to 0"))
+	}
+	if params.Gamma != nil && *params.Gamma < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("gamma"), *params.Gamma, "must be greater than or equal to 0"))
+	}
+	allErrs = append(allErrs, validateRatio(params.Eta, fldPath.Child("eta"))...)
+	allErrs = append(allErrs, validateRatio(params.Subsample, fldPath.Child("subsample"))...)
+	allErrs = append(allErrs, validateRatio(params.ColsampleByTree, fldPath.Child("colsampleByTree"))...)
+
+	if params.TreeMethod != "" && !contains(supportedTreeMethods, params.TreeMethod) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("treeMethod"), params.TreeMethod, supportedTreeMethods))
+	}
+	for i, metric := range params.EvalMetric {
+		if metric == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("evalMetric").Index(i), ""))
+		}
+	}
+	for key := range params.Extra {
+		if key == "" {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("extra"), key, "parameter name must not be empty"))
+		}
+	}
+	return allErrs
+}
+
+// validateRatio checks that value is in (0, 1].
+func validateRatio(value *float64, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if value != nil && (*value <= 0 || *value > 1) {
+		allErrs = append(allErrs, field.Invalid(fldPath, *value, "must be greater than 0 and less than or equal to 1"))
+	}
+	return allErrs
+}
+
+func validateReplicaSpec(rtype commonv1.ReplicaType, spec *commonv1.ReplicaSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if rtype != commonv1.ReplicaType(v1xgboost.XGBoostReplicaTypeMaster) &&
+		rtype != commonv1.ReplicaType(v1xgboost.XGBoostReplicaTypeWorker) {
+		allErrs = append(allErrs, field.NotSupported(fldPath, string(rtype), []string{
+			string(v1xgboost.XGBoostReplicaTypeMaster), string(v1xgboost.XGBoostReplicaTypeWorker)}))
+		return allErrs
+	}
+	if spec == nil {
+		allErrs = append(allErrs, field.Required(fldPath, ""))
+		return allErrs
+	}
+
+	replicasPath := fldPath.Child("replicas")
+	if spec.Replicas == nil {
+		allErrs = append(allErrs, field.Required(replicasPath, ""))
+	} else if rtype == commonv1.ReplicaType(v1xgboost.XGBoostReplicaTypeMaster) && *spec.Replicas != 1 {
+		allErrs = append(allErrs, field.Invalid(replicasPath, *spec.Replicas, "Master replicas must be 1"))
+	} else if *spec.Replicas < 0 {
+		allErrs = append(allErrs, field.Invalid(replicasPath, *spec.Replicas, "must be greater than or equal to 0"))
+	}
+
+	containersPath := fldPath.Child("template", "spec", "containers")
+	containers := spec.Template.Spec.Containers
+	if len(containers) == 0 {
+		allErrs = append(allErrs, field.Required(containersPath, ""))
+		return allErrs
+	}
+
+	foundContainer := false
+	for i, container := range containers {
+		if container.Image == "" {
+			allErrs = append(allErrs, field.Required(containersPath.Index(i).Child("image"), ""))
+		}
+		if container.Name != v1xgboost.DefaultContainerName {
+			continue
+		}
+		foundContainer = true
+		if findPort(container) < 0 {
+			allErrs = append(allErrs, field.Required(containersPath.Index(i).Child("ports"),
+				fmt.Sprintf("a port named %s is required", v1xgboost.DefaultContainerPortName)))
+		}
+	}
+	if !foundContainer {
+		allErrs = append(allErrs, field.Required(containersPath,
+			fmt.Sprintf("a container named %s is required", v1xgboost.DefaultContainerName)))
+	}
+	return allErrs
+}
+
+// isStarted returns true once the controller has observed the job.
+func isStarted(job *v1xgboost.XGBoostJob) bool {
+	return len(job.Status.Conditions) > 0
+}
+
+// getPort returns the xgboostjob port of the replica, or -1 if there is none.
+func getPort(spec *commonv1.ReplicaSpec) int32 {
+	for _, container := range spec.Template.Spec.Containers {
+		if container.Name == v1xgboost.DefaultContainerName {
+			return findPort(container)
+		}
+	}
+	return -1
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// getTotalReplicas returns the number of replicas of the job.
+func getTotalReplicas(specs map[commonv1.ReplicaType]*commonv1.ReplicaSpec) int32 {
+	var replicas int32
+	for _, spec := range specs {
+		if spec != nil && spec.Replicas != nil {
+			replicas += *spec.Replicas
+		}
+	}
+	return replicas
+}
+
+// isAbsoluteURI returns true if uri has a scheme and a host, like oss://bucket/path.
+func isAbsoluteURI(uri string) bool {
+	u, err := url.Parse(uri)
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
+
+func hasVolume(spec corev1.PodSpec, name string) bool {
+	for _, volume := range spec.Volumes {
+		if volume.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func findPort(container corev1.Container) int32 {
+	for _, port := range container.Ports {
+		if port.Name == v1xgboost.DefaultContainerPortName {
+			return port.ContainerPort
+		}
+	}
+	return -1
+}
@@ -0,0 +1,314 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"testing"
+
+	commonv1 "github.com/kubeflow/common/pkg/apis/common/v1"
+	v1xgboost "github.com/kubeflow/xgboost-operator/pkg/apis/xgboostjob/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newReplicaSpec(replicas int32) *commonv1.ReplicaSpec {
+	return &commonv1.ReplicaSpec{
+		Replicas: &replicas,
+		Template: corev1.PodTemplateSpec{
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{
+						Name:  v1xgboost.DefaultContainerName,
+						Image: "test-image-for-kubeflow-xgboost-operator:latest",
+						Ports: []corev1.ContainerPort{
+							{
+								Name:          v1xgboost.DefaultContainerPortName,
+								ContainerPort: v1xgboost.DefaultPort,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func newJob(master, worker int32) *v1xgboost.XGBoostJob {
+	job := &v1xgboost.XGBoostJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-xgboostjob",
+			Namespace: metav1.NamespaceDefault,
+		},
+		Spec: v1xgboost.XGBoostJobSpec{
+			XGBReplicaSpecs: map[commonv1.ReplicaType]*commonv1.ReplicaSpec{},
+		},
+	}
+	if master > 0 {
+		job.Spec.XGBReplicaSpecs[commonv1.ReplicaType(v1xgboost.XGBoostReplicaTypeMaster)] = newReplicaSpec(master)
+	}
+	if worker > 0 {
+		job.Spec.XGBReplicaSpecs[commonv1.ReplicaType(v1xgboost.XGBoostReplicaTypeWorker)] = newReplicaSpec(worker)
+	}
+	return job
+}
+
+func TestValidateV1XGBoostJob(t *testing.T) {
+	noContainer := newJob(1, 2)
+	noContainer.Spec.XGBReplicaSpecs[commonv1.ReplicaType(v1xgboost.XGBoostReplicaTypeWorker)].Template.Spec.Containers[0].Name = "main"
+
+	noPort := newJob(1, 2)
+	noPort.Spec.XGBReplicaSpecs[commonv1.ReplicaType(v1xgboost.XGBoostReplicaTypeMaster)].Template.Spec.Containers[0].Ports = nil
+
+	unknownType := newJob(1, 0)
+	unknownType.Spec.XGBReplicaSpecs["PS"] = newReplicaSpec(1)
+
+	numRound, eta := int32(10), 0.3
+	withParams := newJob(1, 2)
+	withParams.Spec.XGBParams = &v1xgboost.XGBoostParams{
+		Objective:  "binary:logistic",
+		NumRound:   &numRound,
+		Eta:        &eta,
+		EvalMetric: []string{"auc"},
+		TreeMethod: "hist",
+	}
+
+	zeroRound, bigEta := int32(0), 1.5
+	invalidParams := newJob(1, 2)
+	invalidParams.Spec.XGBParams = &v1xgboost.XGBoostParams{
+		NumRound:   &zeroRound,
+		Eta:        &bigEta,
+		TreeMethod: "fast",
+	}
+
+	lightGBM := newJob(1, 2)
+	lightGBM.Spec.Framework = v1xgboost.FrameworkLightGBM
+
+	lightGBMPorts := lightGBM.DeepCopy()
+	lightGBMPorts.Spec.XGBReplicaSpecs[commonv1.ReplicaType(v1xgboost.XGBoostReplicaTypeWorker)].Template.Spec.Containers[0].Ports[0].ContainerPort = 9991
+
+	unknownFramework := newJob(1, 2)
+	unknownFramework.Spec.Framework = "CatBoost"
+
+	allWorkers := newJob(1, 2)
+	allWorkers.Spec.SuccessPolicy = v1xgboost.SuccessPolicyAllWorkersCompleted
+
+	unknownSuccessPolicy := newJob(1, 2)
+	unknownSuccessPolicy.Spec.SuccessPolicy = "AnyWorkerCompleted"
+
+	jobRestartScope := newJob(1, 2)
+	jobRestartScope.Spec.RestartScope = v1xgboost.RestartScopeJob
+
+	unknownRestartScope := newJob(1, 2)
+	unknownRestartScope.Spec.RestartScope = "Replica"
+
+	progressDeadline := int64(600)
+	withProgressDeadline := newJob(1, 2)
+	withProgressDeadline.Spec.ProgressDeadlineSeconds = &progressDeadline
+
+	noProgressDeadline := int64(0)
+	invalidProgressDeadline := newJob(1, 2)
+	invalidProgressDeadline.Spec.ProgressDeadlineSeconds = &noProgressDeadline
+
+	withClaim := newJob(1, 2)
+	withClaim.Spec.Checkpoint = &v1xgboost.CheckpointSpec{ClaimName: "checkpoints", Path: "/checkpoints"}
+
+	withVolume := newJob(1, 2)
+	withVolume.Spec.Checkpoint = &v1xgboost.CheckpointSpec{VolumeName: "checkpoints"}
+	for _, spec := range withVolume.Spec.XGBReplicaSpecs {
+		spec.Template.Spec.Volumes = []corev1.Volume{{Name: "checkpoints"}}
+	}
+
+	missingVolume := newJob(1, 2)
+	missingVolume.Spec.Checkpoint = &v1xgboost.CheckpointSpec{VolumeName: "checkpoints"}
+	missingVolume.Spec.XGBReplicaSpecs[commonv1.ReplicaType(v1xgboost.XGBoostReplicaTypeMaster)].Template.Spec.Volumes = []corev1.Volume{{Name: "checkpoints"}}
+
+	zeroInterval := int32(0)
+	invalidCheckpoint := newJob(1, 2)
+	invalidCheckpoint.Spec.Checkpoint = &v1xgboost.CheckpointSpec{Path: "checkpoints", Interval: &zeroInterval}
+
+	withOutputClaim := newJob(1, 2)
+	withOutputClaim.Spec.Output = &v1xgboost.OutputSpec{ClaimName: "models", Path: "/models", SubPath: "iris"}
+
+	withOutputURI := newJob(1, 2)
+	withOutputURI.Spec.Output = &v1xgboost.OutputSpec{URI: "oss://models/iris.json", SecretName: "oss-credentials"}
+
+	noOutput := newJob(1, 2)
+	noOutput.Spec.Output = &v1xgboost.OutputSpec{SecretName: "oss-credentials"}
+
+	invalidOutput := newJob(1, 2)
+	invalidOutput.Spec.Output = &v1xgboost.OutputSpec{URI: "models/iris.json", Path: "/models", SubPath: "iris"}
+
+	byFile := newJob(1, 2)
+	byFile.Spec.Data = &v1xgboost.DataSpec{ClaimName: "iris", Files: []string{"iris-0.csv", "iris-1.csv", "iris-2.csv"}}
+
+	tooFewFiles := newJob(1, 2)
+	tooFewFiles.Spec.Data = &v1xgboost.DataSpec{URI: "oss://datasets/iris", Files: []string{"iris-0.csv", "/iris-1.csv"}}
+
+	uriOnly := newJob(1, 2)
+	uriOnly.Spec.Data = &v1xgboost.DataSpec{URI: "oss://datasets/iris",
+		Download: &v1xgboost.DataDownloadSpec{Image: "downloader"}}
+
+	rows := int64(150)
+	byRowRange := newJob(1, 2)
+	byRowRange.Spec.Data = &v1xgboost.DataSpec{URI: "oss://datasets/iris", Sharding: v1xgboost.ShardingModeByRowRange, Rows: &rows}
+
+	noRows := newJob(1, 2)
+	noRows.Spec.Data = &v1xgboost.DataSpec{URI: "oss://datasets/iris", Sharding: v1xgboost.ShardingModeByRowRange}
+
+	unknownSharding := newJob(1, 2)
+	unknownSharding.Spec.Data = &v1xgboost.DataSpec{ClaimName: "iris", Sharding: "ByColumn"}
+
+	withDownload := newJob(1, 2)
+	withDownload.Spec.Data = &v1xgboost.DataSpec{URI: "oss://datasets/iris", Sharding: v1xgboost.ShardingModeByRowRange, Rows: &rows,
+		Download: &v1xgboost.DataDownloadSpec{Image: "downloader", Destination: "/data", SecretName: "oss-credentials"}}
+
+	invalidDownload := newJob(1, 2)
+	invalidDownload.Spec.Data = &v1xgboost.DataSpec{ClaimName: "iris", Files: []string{"iris-0.csv", "iris-1.csv", "iris-2.csv"},
+		Download: &v1xgboost.DataDownloadSpec{Destination: "data"}}
+
+	predict := newJob(1, 2)
+	predict.Spec.JobType = v1xgboost.JobTypePredict
+	predict.Spec.ModelFrom = &v1xgboost.ModelSource{URI: "pvc://models/iris/model.json"}
+
+	predictWithoutModel := newJob(1, 2)
+	predictWithoutModel.Spec.JobType = v1xgboost.JobTypePredict
+
+	evaluateWithOutput := newJob(1, 2)
+	evaluateWithOutput.Spec.JobType = v1xgboost.JobTypeEvaluate
+	evaluateWithOutput.Spec.ModelFrom = &v1xgboost.ModelSource{URI: "model.json"}
+	evaluateWithOutput.Spec.Output = &v1xgboost.OutputSpec{URI: "oss://models/iris.json"}
+
+	predictByRef := newJob(1, 2)
+	predictByRef.Spec.JobType = v1xgboost.JobTypePredict
+	predictByRef.Spec.ModelFrom = &v1xgboost.ModelSource{XGBoostJobRef: "iris-train"}
+
+	predictWithTwoModels := newJob(1, 2)
+	predictWithTwoModels.Spec.JobType = v1xgboost.JobTypePredict
+	predictWithTwoModels.Spec.ModelFrom = &v1xgboost.ModelSource{URI: "oss://models/iris.json", XGBoostJobRef: "iris-train"}
+
+	predictWithEmptyModel := newJob(1, 2)
+	predictWithEmptyModel.Spec.JobType = v1xgboost.JobTypePredict
+	predictWithEmptyModel.Spec.ModelFrom = &v1xgboost.ModelSource{SecretName: "oss-credentials"}
+
+	unknownJobType := newJob(1, 2)
+	unknownJobType.Spec.JobType = "Serve"
+
+	noWorker := newJob(0, 1)
+	*noWorker.Spec.XGBReplicaSpecs[commonv1.ReplicaType(v1xgboost.XGBoostReplicaTypeWorker)].Replicas = 0
+
+	onlyUnknownType := newJob(0, 0)
+	onlyUnknownType.Spec.XGBReplicaSpecs["PS"] = newReplicaSpec(1)
+
+	type tc struct {
+		job          *v1xgboost.XGBoostJob
+		expectedErrs int
+	}
+	testCase := []tc{
+		tc{job: newJob(1, 0), expectedErrs: 0},
+		tc{job: newJob(1, 2), expectedErrs: 0},
+		tc{job: newJob(0, 0), expectedErrs: 1},
+		tc{job: newJob(0, 2), expectedErrs: 0},
+		tc{job: noWorker, expectedErrs: 1},
+		tc{job: onlyUnknownType, expectedErrs: 2},
+		tc{job: newJob(2, 2), expectedErrs: 1},
+		tc{job: noContainer, expectedErrs: 1},
+		tc{job: noPort, expectedErrs: 1},
+		tc{job: unknownType, expectedErrs: 1},
+		tc{job: withParams, expectedErrs: 0},
+		tc{job: invalidParams, expectedErrs: 3},
+		tc{job: lightGBM, expectedErrs: 0},
+		tc{job: lightGBMPorts, expectedErrs: 1},
+		tc{job: unknownFramework, expectedErrs: 1},
+		tc{job: allWorkers, expectedErrs: 0},
+		tc{job: unknownSuccessPolicy, expectedErrs: 1},
+		tc{job: jobRestartScope, expectedErrs: 0},
+		tc{job: unknownRestartScope, expectedErrs: 1},
+		tc{job: withProgressDeadline, expectedErrs: 0},
+		tc{job: invalidProgressDeadline, expectedErrs: 1},
+		tc{job: withClaim, expectedErrs: 0},
+		tc{job: withVolume, expectedErrs: 0},
+		tc{job: missingVolume, expectedErrs: 1},
+		tc{job: invalidCheckpoint, expectedErrs: 3},
+		tc{job: withOutputClaim, expectedErrs: 0},
+		tc{job: withOutputURI, expectedErrs: 0},
+		tc{job: noOutput, expectedErrs: 1},
+		tc{job: invalidOutput, expectedErrs: 3},
+		tc{job: byFile, expectedErrs: 0},
+		tc{job: tooFewFiles, expectedErrs: 2},
+		tc{job: uriOnly, expectedErrs: 0},
+		tc{job: byRowRange, expectedErrs: 0},
+		tc{job: noRows, expectedErrs: 1},
+		tc{job: unknownSharding, expectedErrs: 1},
+		tc{job: withDownload, expectedErrs: 0},
+		tc{job: predict, expectedErrs: 0},
+		tc{job: predictWithoutModel, expectedErrs: 1},
+		tc{job: predictByRef, expectedErrs: 0},
+		tc{job: predictWithTwoModels, expectedErrs: 1},
+		tc{job: predictWithEmptyModel, expectedErrs: 1},
+		tc{job: evaluateWithOutput, expectedErrs: 2},
+		tc{job: unknownJobType, expectedErrs: 2},
+		tc{job: invalidDownload, expectedErrs: 3},
+	}
+	for i, c := range testCase {
+		errs := ValidateV1XGBoostJob(c.job)
+		if len(errs) != c.expectedErrs {
+			t.Errorf("Case %d: got %d errors %v. Expected %d", i, len(errs), errs, c.expectedErrs)
+		}
+	}
+}
+
+func TestValidateV1XGBoostJobUpdate(t *testing.T) {
+	started := newJob(1, 2)
+	started.Status.Conditions = []commonv1.JobCondition{{Type: commonv1.JobCreated, Status: corev1.ConditionTrue}}
+
+	scaled := started.DeepCopy()
+	*scaled.Spec.XGBReplicaSpecs[commonv1.ReplicaType(v1xgboost.XGBoostReplicaTypeWorker)].Replicas = 3
+
+	ttl := int32(60)
+	withTTL := started.DeepCopy()
+	withTTL.Spec.RunPolicy.TTLSecondsAfterFinished = &ttl
+
+	lightGBM := started.DeepCopy()
+	lightGBM.Spec.Framework = v1xgboost.FrameworkLightGBM
+
+	withData := started.DeepCopy()
+	withData.Spec.Data = &v1xgboost.DataSpec{ClaimName: "iris", Files: []string{"iris-0.csv", "iris-1.csv", "iris-2.csv"}}
+
+	predict := started.DeepCopy()
+	predict.Spec.JobType = v1xgboost.JobTypePredict
+	predict.Spec.ModelFrom = &v1xgboost.ModelSource{URI: "oss://models/iris.json"}
+
+	type tc struct {
+		oldJob       *v1xgboost.XGBoostJob
+		newJob       *v1xgboost.XGBoostJob
+		expectedErrs int
+	}
+	testCase := []tc{
+		tc{oldJob: newJob(1, 2), newJob: newJob(1, 3), expectedErrs: 0},
+		tc{oldJob: started, newJob: scaled, expectedErrs: 1},
+		tc{oldJob: started, newJob: newJob(1, 0), expectedErrs: 1},
+		tc{oldJob: started, newJob: withTTL, expectedErrs: 0},
+		tc{oldJob: started, newJob: lightGBM, expectedErrs: 1},
+		tc{oldJob: started, newJob: withData, expectedErrs: 1},
+		tc{oldJob: started, newJob: predict, expectedErrs: 1},
+	}
+	for i, c := range testCase {
+		errs := ValidateV1XGBoostJobUpdate(c.newJob, c.oldJob)
+		if len(errs) != c.expectedErrs {
+			t.Errorf("Case %d: got %d errors %v. Expected %d", i, len(errs), errs, c.expectedErrs)
+		}
+	}
+}
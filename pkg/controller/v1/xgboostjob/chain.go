@@ -0,0 +1,147 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xgboostjob
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	commonutil "github.com/kubeflow/common/pkg/util"
+	logger "github.com/kubeflow/common/pkg/util"
+	v1xgboost "github.com/kubeflow/xgboost-operator/pkg/apis/xgboostjob/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// waitingForModelReason is the reason of the event of a job waiting for the job of its
+	// modelFrom.xgboostJobRef to succeed.
+	waitingForModelReason = "WaitingForModel"
+	// modelSourceFailedReason is added in a job when the job of its modelFrom.xgboostJobRef
+	// failed, or succeeded without saving a model.
+	modelSourceFailedReason = "ModelSourceFailed"
+
+	// modelSourceCreationTimeout is how long after its creation a job waits for the job of
+	// its modelFrom.xgboostJobRef to be created before it is failed. The referenced job may
+	// have been deleted, e.g. by its ttlSecondsAfterFinished, and would never come back.
+	modelSourceCreationTimeout = 10 * time.Minute
+)
+
+// getInputModel returns the URI of the model of the job, or "" if it has none or the job
+// of its modelFrom.xgboostJobRef has not succeeded yet.
+func getInputModel(job *v1xgboost.XGBoostJob) string {
+	if job.Spec.ModelFrom == nil {
+		return ""
+	}
+	if job.Spec.ModelFrom.URI != "" {
+		return job.Spec.ModelFrom.URI
+	}
+	return job.Status.InputModel
+}
+
+// resolveModelSource records in the status of a job with a modelFrom.xgboostJobRef the
+// model saved by the referenced job once it has succeeded, and returns true when the pods of
+// the job can be created. The job is failed if the referenced job failed, saved no model, or
+// was not created within modelSourceCreationTimeout.
+func (r *ReconcileXGBoostJob) resolveModelSource(xgboostJob *v1xgboost.XGBoostJob) (bool, error) {
+	modelFrom := xgboostJob.Spec.ModelFrom
+	if modelFrom == nil || modelFrom.XGBoostJobRef == "" || xgboostJob.Status.InputModel != "" || isFinished(xgboostJob) {
+		return true, nil
+	}
+	if modelFrom.XGBoostJobRef == xgboostJob.Name {
+		msg := fmt.Sprintf("XGBoostJob %s is failed because it takes its model from itself.", xgboostJob.Name)
+		return false, r.failJob(xgboostJob, modelSourceFailedReason, msg)
+	}
+
+	source := &v1xgboost.XGBoostJob{}
+	err := r.Get(context.Background(), types.NamespacedName{Namespace: xgboostJob.Namespace, Name: modelFrom.XGBoostJobRef}, source)
+	if errors.IsNotFound(err) {
+		if timeUntilModelSourceDeadline(xgboostJob) == 0 {
+			msg := fmt.Sprintf("XGBoostJob %s is failed because XGBoostJob %s of its modelFrom was not found within %v.",
+				xgboostJob.Name, modelFrom.XGBoostJobRef, modelSourceCreationTimeout)
+			return false, r.failJob(xgboostJob, modelSourceFailedReason, msg)
+		}
+		msg := fmt.Sprintf("XGBoostJob %s is waiting for XGBoostJob %s to be created.", xgboostJob.Name, modelFrom.XGBoostJobRef)
+		r.Recorder.Event(xgboostJob, corev1.EventTypeNormal, waitingForModelReason, msg)
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	switch {
+	case commonutil.IsFailed(source.Status.JobStatus):
+		msg := fmt.Sprintf("XGBoostJob %s is failed because XGBoostJob %s of its modelFrom failed.", xgboostJob.Name, source.Name)
+		return false, r.failJob(xgboostJob, modelSourceFailedReason, msg)
+	case !commonutil.IsSucceeded(source.Status.JobStatus):
+		msg := fmt.Sprintf("XGBoostJob %s is waiting for XGBoostJob %s to succeed.", xgboostJob.Name, source.Name)
+		r.Recorder.Event(xgboostJob, corev1.EventTypeNormal, waitingForModelReason, msg)
+		return false, nil
+	case source.Status.Model == nil || source.Status.Model.URI == "":
+		msg := fmt.Sprintf("XGBoostJob %s is failed because XGBoostJob %s of its modelFrom saved no model.", xgboostJob.Name, source.Name)
+		return false, r.failJob(xgboostJob, modelSourceFailedReason, msg)
+	}
+
+	logger.LoggerForJob(xgboostJob).Infof("Take the model %s of XGBoostJob %s", source.Status.Model.URI, source.Name)
+	modified := xgboostJob.DeepCopy()
+	modified.Status.InputModel = source.Status.Model.URI
+	if err := r.patchStatus(xgboostJob, modified); err != nil {
+		return false, err
+	}
+	*xgboostJob = *modified
+	return true, nil
+}
+
+// timeUntilModelSourceDeadline returns the time left to a job waiting for the job of its
+// modelFrom.xgboostJobRef to be created, or 0 if the deadline has passed.
+func timeUntilModelSourceDeadline(job *v1xgboost.XGBoostJob) time.Duration {
+	left := time.Until(job.CreationTimestamp.Add(modelSourceCreationTimeout))
+	if left <= 0 {
+		return 0
+	}
+	if left < time.Second {
+		return time.Second
+	}
+	return left
+}
+
+// newModelConsumersMapper returns a handler.Mapper enqueuing the jobs waiting for the model
+// of a job, so that they are reconciled when it finishes.
+func newModelConsumersMapper(c client.Client) handler.Mapper {
+	return handler.ToRequestsFunc(func(obj handler.MapObject) []reconcile.Request {
+		jobs := &v1xgboost.XGBoostJobList{}
+		if err := c.List(context.Background(), jobs, client.InNamespace(obj.Meta.GetNamespace())); err != nil {
+			log.Error(err, "failed to list the XGBoostJobs taking their model from a job", "job", obj.Meta.GetName())
+			return nil
+		}
+		var requests []reconcile.Request
+		for _, job := range jobs.Items {
+			modelFrom := job.Spec.ModelFrom
+			if modelFrom == nil || modelFrom.XGBoostJobRef != obj.Meta.GetName() || job.Status.InputModel != "" {
+				continue
+			}
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: job.Namespace, Name: job.Name},
+			})
+		}
+		return requests
+	})
+}
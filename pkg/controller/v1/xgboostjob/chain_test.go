@@ -0,0 +1,117 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xgboostjob
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	commonv1 "github.com/kubeflow/common/pkg/apis/common/v1"
+	commonutil "github.com/kubeflow/common/pkg/util"
+	v1xgboost "github.com/kubeflow/xgboost-operator/pkg/apis/xgboostjob/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestResolveModelSource(t *testing.T) {
+	s := runtime.NewScheme()
+	if err := scheme.AddToScheme(s); err != nil {
+		t.Fatalf("Failed to build the scheme: %v", err)
+	}
+	if err := v1xgboost.AddToScheme(s); err != nil {
+		t.Fatalf("Failed to build the scheme: %v", err)
+	}
+
+	newSource := func(condition commonv1.JobConditionType, model *v1xgboost.ModelStatus) *v1xgboost.XGBoostJob {
+		source := NewXGBoostJobWithMaster(1)
+		source.Name = "iris-train"
+		source.Status.Model = model
+		if condition != "" {
+			if err := commonutil.UpdateJobConditions(&source.Status.JobStatus, condition, "", ""); err != nil {
+				t.Fatalf("Failed to update the conditions: %v", err)
+			}
+		}
+		return source
+	}
+	model := &v1xgboost.ModelStatus{URI: "pvc://models/iris/model.json"}
+
+	type tc struct {
+		source        *v1xgboost.XGBoostJob
+		age           time.Duration
+		expectedReady bool
+		expectedModel string
+		expectFailed  bool
+	}
+	testCase := []tc{
+		tc{source: nil, expectedReady: false},
+		// The referenced job was deleted, or never created.
+		tc{source: nil, age: modelSourceCreationTimeout + time.Minute, expectedReady: false, expectFailed: true},
+		tc{source: newSource(commonv1.JobRunning, nil), expectedReady: false},
+		tc{source: newSource(commonv1.JobSucceeded, model), expectedReady: true, expectedModel: model.URI},
+		tc{source: newSource(commonv1.JobSucceeded, nil), expectedReady: false, expectFailed: true},
+		tc{source: newSource(commonv1.JobFailed, nil), expectedReady: false, expectFailed: true},
+	}
+	for i, c := range testCase {
+		job := NewXGBoostJobWithMaster(1)
+		job.Spec.JobType = v1xgboost.JobTypePredict
+		job.Spec.ModelFrom = &v1xgboost.ModelSource{XGBoostJobRef: "iris-train"}
+		job.CreationTimestamp = metav1.NewTime(time.Now().Add(-c.age))
+		objs := []runtime.Object{job.DeepCopy()}
+		if c.source != nil {
+			objs = append(objs, c.source)
+		}
+		r := &ReconcileXGBoostJob{}
+		r.JobController.Controller = r
+		recorder := record.NewFakeRecorder(10)
+		r.Recorder, r.recorder = recorder, recorder
+		r.Client = fake.NewFakeClientWithScheme(s, objs...)
+
+		ready, err := r.resolveModelSource(job)
+		if err != nil {
+			t.Fatalf("Case %d: failed to resolve the model source: %v", i, err)
+		}
+		if ready != c.expectedReady || job.Status.InputModel != c.expectedModel {
+			t.Errorf("Case %d: Got ready %v and model %q. Expected %v and %q", i, ready, job.Status.InputModel, c.expectedReady, c.expectedModel)
+		}
+		latest := &v1xgboost.XGBoostJob{}
+		if err := r.Get(context.Background(), types.NamespacedName{Namespace: job.Namespace, Name: job.Name}, latest); err != nil {
+			t.Fatalf("Case %d: failed to get the job: %v", i, err)
+		}
+		if failed := commonutil.IsFailed(latest.Status.JobStatus); failed != c.expectFailed {
+			t.Errorf("Case %d: Got failed %v. Expected %v", i, failed, c.expectFailed)
+		}
+		if len(recorder.Events) != 1 && !ready {
+			t.Errorf("Case %d: Got %d events. Expected 1", i, len(recorder.Events))
+		}
+
+		// The resolved model is given to the pods.
+		if ready {
+			podTemplate := &corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: v1xgboost.DefaultContainerName}}}}
+			if err := setPodModel(job, podTemplate); err != nil {
+				t.Fatalf("Case %d: failed to set pod model: %v", i, err)
+			}
+			if env := podTemplate.Spec.Containers[0].Env[0]; env.Name != envInputModel || env.Value != "/xgboostjob/model/iris/model.json" {
+				t.Errorf("Case %d: Got env %v", i, env)
+			}
+		}
+	}
+}
@@ -0,0 +1,110 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xgboostjob
+
+import (
+	"strconv"
+
+	logger "github.com/kubeflow/common/pkg/util"
+	v1xgboost "github.com/kubeflow/xgboost-operator/pkg/apis/xgboostjob/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// checkpointVolumeName is the name of the volume of the checkpoint claim.
+	checkpointVolumeName = "xgboostjob-checkpoint"
+
+	// envCheckpointDir is the directory where the checkpoints are saved.
+	envCheckpointDir = "XGBOOST_CHECKPOINT_DIR"
+	// envCheckpointInterval is the number of boosting rounds between two checkpoints.
+	envCheckpointInterval = "XGBOOST_CHECKPOINT_INTERVAL"
+	// envCheckpointLatest is the path of the checkpoint to resume from, if any.
+	envCheckpointLatest = "XGBOOST_CHECKPOINT_LATEST"
+)
+
+// setPodCheckpoint mounts the checkpoint volume in every container of the pod and
+// tells them where to save their checkpoints and which one to resume from.
+func setPodCheckpoint(job *v1xgboost.XGBoostJob, podTemplate *corev1.PodTemplateSpec) {
+	checkpoint := job.Spec.Checkpoint
+	if checkpoint == nil {
+		return
+	}
+
+	volumeName := checkpoint.VolumeName
+	if checkpoint.ClaimName != "" {
+		volumeName = checkpointVolumeName
+		podTemplate.Spec.Volumes = append(podTemplate.Spec.Volumes, corev1.Volume{
+			Name: checkpointVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: checkpoint.ClaimName},
+			},
+		})
+	}
+	mountPath := checkpoint.Path
+	if mountPath == "" {
+		mountPath = v1xgboost.DefaultCheckpointPath
+	}
+
+	envs := []corev1.EnvVar{{Name: envCheckpointDir, Value: mountPath}}
+	if checkpoint.Interval != nil {
+		envs = append(envs, corev1.EnvVar{Name: envCheckpointInterval, Value: strconv.Itoa(int(*checkpoint.Interval))})
+	}
+	if job.Status.LastCheckpoint != nil {
+		envs = append(envs, corev1.EnvVar{Name: envCheckpointLatest, Value: job.Status.LastCheckpoint.Path})
+	}
+	for i := range podTemplate.Spec.Containers {
+		podTemplate.Spec.Containers[i].Env = append(podTemplate.Spec.Containers[i].Env, envs...)
+		podTemplate.Spec.Containers[i].VolumeMounts = append(podTemplate.Spec.Containers[i].VolumeMounts, corev1.VolumeMount{
+			Name:      volumeName,
+			MountPath: mountPath,
+		})
+	}
+}
+
+// syncLastCheckpoint reports in the job status the last checkpoint annotated on the newest
+// coordinator pod, so that the pods created from now on resume from it.
+func (r *ReconcileXGBoostJob) syncLastCheckpoint(xgboostJob *v1xgboost.XGBoostJob) error {
+	if xgboostJob.Spec.Checkpoint == nil {
+		return nil
+	}
+
+	pods, err := r.getCoordinatorPods(xgboostJob)
+	if err != nil {
+		return err
+	}
+
+	// The pod of the last attempt has the newest checkpoint.
+	var lastCheckpoint string
+	var created metav1.Time
+	for _, pod := range pods {
+		if path := pod.Annotations[v1xgboost.LastCheckpointAnnotation]; path != "" && !pod.CreationTimestamp.Before(&created) {
+			lastCheckpoint, created = path, pod.CreationTimestamp
+		}
+	}
+	if lastCheckpoint == "" || (xgboostJob.Status.LastCheckpoint != nil && xgboostJob.Status.LastCheckpoint.Path == lastCheckpoint) {
+		return nil
+	}
+
+	logger.LoggerForJob(xgboostJob).Infof("Found checkpoint %s", lastCheckpoint)
+	modified := xgboostJob.DeepCopy()
+	modified.Status.LastCheckpoint = &v1xgboost.CheckpointStatus{Path: lastCheckpoint, ReportTime: metav1.Now()}
+	if err := r.patchStatus(xgboostJob, modified); err != nil {
+		return err
+	}
+	*xgboostJob = *modified
+	return nil
+}
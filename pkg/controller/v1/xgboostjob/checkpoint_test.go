@@ -0,0 +1,121 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xgboostjob
+
+import (
+	"testing"
+	"time"
+
+	commonv1 "github.com/kubeflow/common/pkg/apis/common/v1"
+	v1xgboost "github.com/kubeflow/xgboost-operator/pkg/apis/xgboostjob/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestPodCheckpoint(t *testing.T) {
+	interval := int32(10)
+	withClaim := NewXGBoostJobWithMaster(1)
+	withClaim.Spec.Checkpoint = &v1xgboost.CheckpointSpec{ClaimName: "checkpoints", Interval: &interval}
+
+	withVolume := NewXGBoostJobWithMaster(1)
+	withVolume.Spec.Checkpoint = &v1xgboost.CheckpointSpec{VolumeName: "checkpoints", Path: "/checkpoints"}
+	withVolume.Status.LastCheckpoint = &v1xgboost.CheckpointStatus{Path: "/checkpoints/round-20.model"}
+
+	type tc struct {
+		job            *v1xgboost.XGBoostJob
+		expectedVolume string
+		expectedEnv    map[string]string
+	}
+	testCase := []tc{
+		tc{
+			job:            withClaim,
+			expectedVolume: checkpointVolumeName,
+			expectedEnv:    map[string]string{envCheckpointDir: v1xgboost.DefaultCheckpointPath, envCheckpointInterval: "10", envCheckpointLatest: ""},
+		},
+		tc{
+			job:            withVolume,
+			expectedVolume: "checkpoints",
+			expectedEnv:    map[string]string{envCheckpointDir: "/checkpoints", envCheckpointInterval: "", envCheckpointLatest: "/checkpoints/round-20.model"},
+		},
+	}
+	for i, c := range testCase {
+		podTemplate := c.job.Spec.XGBReplicaSpecs[commonv1.ReplicaType(v1xgboost.XGBoostReplicaTypeWorker)].Template.DeepCopy()
+		setPodCheckpoint(c.job, podTemplate)
+
+		container := podTemplate.Spec.Containers[0]
+		if len(container.VolumeMounts) != 1 || container.VolumeMounts[0].Name != c.expectedVolume || container.VolumeMounts[0].MountPath != c.expectedEnv[envCheckpointDir] {
+			t.Errorf("Case %d: Got volume mounts %v. Expected %s at %s", i, container.VolumeMounts, c.expectedVolume, c.expectedEnv[envCheckpointDir])
+		}
+		if c.job.Spec.Checkpoint.ClaimName != "" && (len(podTemplate.Spec.Volumes) != 1 || podTemplate.Spec.Volumes[0].PersistentVolumeClaim.ClaimName != c.job.Spec.Checkpoint.ClaimName) {
+			t.Errorf("Case %d: Got volumes %v. Expected the claim %s", i, podTemplate.Spec.Volumes, c.job.Spec.Checkpoint.ClaimName)
+		}
+		actual := map[string]string{}
+		for _, env := range container.Env {
+			actual[env.Name] = env.Value
+		}
+		for name, val := range c.expectedEnv {
+			if actual[name] != val {
+				t.Errorf("Case %d: for name %s Got %s. Expected %s", i, name, actual[name], val)
+			}
+		}
+	}
+}
+
+func TestSyncLastCheckpoint(t *testing.T) {
+	s := runtime.NewScheme()
+	if err := scheme.AddToScheme(s); err != nil {
+		t.Fatalf("Failed to build the scheme: %v", err)
+	}
+	if err := v1xgboost.AddToScheme(s); err != nil {
+		t.Fatalf("Failed to build the scheme: %v", err)
+	}
+
+	job := NewXGBoostJobWithMaster(1)
+	job.Spec.Checkpoint = &v1xgboost.CheckpointSpec{ClaimName: "checkpoints"}
+	r := &ReconcileXGBoostJob{}
+	r.JobController.Controller = r
+	newPod := func(rtype v1xgboost.XGBoostJobReplicaType, checkpoint string, created time.Time) *corev1.Pod {
+		pod := NewXGBoostPod(job, rtype, 0)
+		pod.Annotations[v1xgboost.LastCheckpointAnnotation] = checkpoint
+		pod.CreationTimestamp = metav1.NewTime(created)
+		return pod
+	}
+	// Only the checkpoints of the newest coordinator pod are reported, whatever the order
+	// the pods are listed in.
+	now := time.Now()
+	old := newPod(v1xgboost.XGBoostReplicaTypeMaster, "/xgboostjob/checkpoint/round-5.model", now.Add(-time.Hour))
+	old.Name += "-old"
+	r.Client = fake.NewFakeClientWithScheme(s, job.DeepCopy(),
+		newPod(v1xgboost.XGBoostReplicaTypeMaster, "/xgboostjob/checkpoint/round-10.model", now), old,
+		newPod(v1xgboost.XGBoostReplicaTypeWorker, "/xgboostjob/checkpoint/round-20.model", now))
+
+	if err := r.syncLastCheckpoint(job); err != nil {
+		t.Fatalf("Failed to sync the last checkpoint: %v", err)
+	}
+	if job.Status.LastCheckpoint == nil || job.Status.LastCheckpoint.Path != "/xgboostjob/checkpoint/round-10.model" {
+		t.Errorf("Got last checkpoint %v. Expected /xgboostjob/checkpoint/round-10.model", job.Status.LastCheckpoint)
+	}
+	reportTime := job.Status.LastCheckpoint.ReportTime
+	if err := r.syncLastCheckpoint(job); err != nil {
+		t.Fatalf("Failed to sync the last checkpoint: %v", err)
+	}
+	if !job.Status.LastCheckpoint.ReportTime.Equal(&reportTime) {
+		t.Errorf("Got the same checkpoint reported again at %v", job.Status.LastCheckpoint.ReportTime)
+	}
+}
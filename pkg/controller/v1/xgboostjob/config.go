@@ -0,0 +1,232 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xgboostjob
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	logger "github.com/kubeflow/common/pkg/util"
+	v1xgboost "github.com/kubeflow/xgboost-operator/pkg/apis/xgboostjob/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// configVolumeName is the name of the volume holding the job ConfigMap.
+	configVolumeName = "xgboostjob-config"
+	// configMountPath is where the job ConfigMap is mounted in every container.
+	configMountPath = "/etc/xgboostjob"
+	// paramsFileName is the key of the XGBoost parameters in the job ConfigMap.
+	paramsFileName = "params.json"
+
+	// envParamsFile is the path of the mounted XGBoost parameters file.
+	envParamsFile = "XGBOOST_PARAMS_FILE"
+	// envParamPrefix prefixes the environment variable of every XGBoost parameter.
+	envParamPrefix = "XGBOOST_PARAM_"
+)
+
+// genConfigMapName returns the name of the ConfigMap of the job.
+func genConfigMapName(jobName string) string {
+	return strings.Replace(jobName+"-config", "/", "-", -1)
+}
+
+// genConfigMapData returns the files of the job ConfigMap, or nil if the job needs none.
+func genConfigMapData(job *v1xgboost.XGBoostJob, strategy ClusterSpecStrategy) (map[string]string, error) {
+	data, err := strategy.ConfigMapData(job)
+	if err != nil {
+		return nil, err
+	}
+	if job.Spec.XGBParams != nil {
+		params, err := json.MarshalIndent(xgbParamsToMap(job.Spec.XGBParams), "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		if data == nil {
+			data = map[string]string{}
+		}
+		data[paramsFileName] = string(params)
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+	return data, nil
+}
+
+// reconcileConfigMap creates or updates the ConfigMap mounted in the pods of the job.
+// It is owned by the job, so it is garbage collected together with the job.
+func (r *ReconcileXGBoostJob) reconcileConfigMap(job *v1xgboost.XGBoostJob) error {
+	strategy, err := r.getClusterSpecStrategy(job)
+	if err != nil {
+		return err
+	}
+	data, err := genConfigMapData(job, strategy)
+	if err != nil || data == nil {
+		return err
+	}
+
+	configMaps := r.KubeClientSet.CoreV1().ConfigMaps(job.Namespace)
+	name := genConfigMapName(job.Name)
+	configMap, err := configMaps.Get(name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		logger.LoggerForJob(job).Infof("Create ConfigMap %s", name)
+		_, err = configMaps.Create(&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            name,
+				Labels:          r.GenLabels(job.Name),
+				OwnerReferences: []metav1.OwnerReference{*r.GenOwnerReference(job)},
+			},
+			Data: data,
+		})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	if reflect.DeepEqual(configMap.Data, data) {
+		return nil
+	}
+
+	logger.LoggerForJob(job).Infof("Update ConfigMap %s", name)
+	configMap = configMap.DeepCopy()
+	configMap.Data = data
+	_, err = configMaps.Update(configMap)
+	return err
+}
+
+// setPodConfig mounts the job ConfigMap with the given files in every container of the
+// pod and exposes the XGBoost parameters as environment variables.
+func setPodConfig(job *v1xgboost.XGBoostJob, podTemplate *corev1.PodTemplateSpec, data map[string]string) {
+	if len(data) == 0 {
+		return
+	}
+
+	var envs []corev1.EnvVar
+	if job.Spec.XGBParams != nil {
+		envs = append(envs, corev1.EnvVar{
+			Name:  envParamsFile,
+			Value: filepath.Join(configMountPath, paramsFileName),
+		})
+		params := xgbParamsToEnv(job.Spec.XGBParams)
+		names := make([]string, 0, len(params))
+		for name := range params {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			envs = append(envs, corev1.EnvVar{Name: name, Value: params[name]})
+		}
+	}
+
+	podTemplate.Spec.Volumes = append(podTemplate.Spec.Volumes, corev1.Volume{
+		Name: configVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: genConfigMapName(job.Name)},
+			},
+		},
+	})
+	for i := range podTemplate.Spec.Containers {
+		podTemplate.Spec.Containers[i].Env = append(podTemplate.Spec.Containers[i].Env, envs...)
+		podTemplate.Spec.Containers[i].VolumeMounts = append(podTemplate.Spec.Containers[i].VolumeMounts, corev1.VolumeMount{
+			Name:      configVolumeName,
+			MountPath: configMountPath,
+			ReadOnly:  true,
+		})
+	}
+}
+
+// xgbParamsToMap returns the parameters keyed by their XGBoost names. Typed parameters
+// take precedence over the same parameter in Extra.
+func xgbParamsToMap(params *v1xgboost.XGBoostParams) map[string]interface{} {
+	m := make(map[string]interface{}, len(params.Extra))
+	for key, val := range params.Extra {
+		m[key] = val
+	}
+	if params.Objective != "" {
+		m["objective"] = params.Objective
+	}
+	if params.NumClass != nil {
+		m["num_class"] = *params.NumClass
+	}
+	if params.NumRound != nil {
+		m["num_round"] = *params.NumRound
+	}
+	if params.MaxDepth != nil {
+		m["max_depth"] = *params.MaxDepth
+	}
+	if params.Eta != nil {
+		m["eta"] = *params.Eta
+	}
+	if params.Gamma != nil {
+		m["gamma"] = *params.Gamma
+	}
+	if params.Subsample != nil {
+		m["subsample"] = *params.Subsample
+	}
+	if params.ColsampleByTree != nil {
+		m["colsample_bytree"] = *params.ColsampleByTree
+	}
+	if len(params.EvalMetric) > 0 {
+		m["eval_metric"] = params.EvalMetric
+	}
+	if params.TreeMethod != "" {
+		m["tree_method"] = params.TreeMethod
+	}
+	if params.Seed != nil {
+		m["seed"] = *params.Seed
+	}
+	return m
+}
+
+// xgbParamsToEnv returns the parameters as environment variables, e.g. max_depth is
+// exposed as XGBOOST_PARAM_MAX_DEPTH. Lists are joined with commas.
+func xgbParamsToEnv(params *v1xgboost.XGBoostParams) map[string]string {
+	envs := map[string]string{}
+	for key, val := range xgbParamsToMap(params) {
+		var s string
+		switch v := val.(type) {
+		case string:
+			s = v
+		case int32:
+			s = strconv.Itoa(int(v))
+		case float64:
+			s = strconv.FormatFloat(v, 'g', -1, 64)
+		case []string:
+			s = strings.Join(v, ",")
+		}
+		envs[envParamPrefix+toEnvName(key)] = s
+	}
+	return envs
+}
+
+// toEnvName turns a parameter name into a valid environment variable name.
+func toEnvName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, name)
+}
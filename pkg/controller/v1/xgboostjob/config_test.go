@@ -0,0 +1,132 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xgboostjob
+
+import (
+	"encoding/json"
+	"testing"
+
+	commonv1 "github.com/kubeflow/common/pkg/apis/common/v1"
+	v1xgboost "github.com/kubeflow/xgboost-operator/pkg/apis/xgboostjob/v1"
+)
+
+func TestSetPodConfig(t *testing.T) {
+	numRound, maxDepth, eta := int32(50), int32(6), 0.1
+	job := NewXGBoostJobWithMaster(1)
+	job.Spec.XGBParams = &v1xgboost.XGBoostParams{
+		Objective:  "multi:softmax",
+		NumRound:   &numRound,
+		MaxDepth:   &maxDepth,
+		Eta:        &eta,
+		EvalMetric: []string{"merror", "mlogloss"},
+		Extra:      map[string]string{"min_child_weight": "2", "max_depth": "3"},
+	}
+
+	data, err := genConfigMapData(job, &xgboostStrategy{})
+	if err != nil {
+		t.Fatalf("Failed to generate ConfigMap data: %v", err)
+	}
+	podTemplate := job.Spec.XGBReplicaSpecs[commonv1.ReplicaType(v1xgboost.XGBoostReplicaTypeWorker)].Template
+	setPodConfig(job, &podTemplate, data)
+
+	expectedEnv := map[string]string{
+		"XGBOOST_PARAMS_FILE":            "/etc/xgboostjob/params.json",
+		"XGBOOST_PARAM_OBJECTIVE":        "multi:softmax",
+		"XGBOOST_PARAM_NUM_ROUND":        "50",
+		"XGBOOST_PARAM_MAX_DEPTH":        "6",
+		"XGBOOST_PARAM_ETA":              "0.1",
+		"XGBOOST_PARAM_EVAL_METRIC":      "merror,mlogloss",
+		"XGBOOST_PARAM_MIN_CHILD_WEIGHT": "2",
+	}
+	actual := map[string]string{}
+	for _, env := range podTemplate.Spec.Containers[0].Env {
+		actual[env.Name] = env.Value
+	}
+	if len(actual) != len(expectedEnv) {
+		t.Errorf("Got %d env vars %v. Expected %d", len(actual), actual, len(expectedEnv))
+	}
+	for name, val := range expectedEnv {
+		if actual[name] != val {
+			t.Errorf("For name %s Got %s. Expected %s", name, actual[name], val)
+		}
+	}
+
+	if len(podTemplate.Spec.Volumes) != 1 || podTemplate.Spec.Volumes[0].ConfigMap.Name != "test-xgboostjob-config" {
+		t.Errorf("Got volumes %v. Expected the job ConfigMap", podTemplate.Spec.Volumes)
+	}
+	if mounts := podTemplate.Spec.Containers[0].VolumeMounts; len(mounts) != 1 || mounts[0].MountPath != configMountPath {
+		t.Errorf("Got volume mounts %v. Expected %s", mounts, configMountPath)
+	}
+
+	params := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(data[paramsFileName]), &params); err != nil {
+		t.Fatalf("Failed to parse %s: %v", paramsFileName, err)
+	}
+	if params["num_round"] != float64(50) || params["max_depth"] != float64(6) || params["min_child_weight"] != "2" {
+		t.Errorf("Got params %v", params)
+	}
+}
+
+func TestSetPodConfigWithoutParams(t *testing.T) {
+	job := NewXGBoostJobWithMaster(1)
+	data, err := genConfigMapData(job, &xgboostStrategy{})
+	if err != nil || data != nil {
+		t.Errorf("Got ConfigMap data %v, %v. Expected none", data, err)
+	}
+
+	podTemplate := job.Spec.XGBReplicaSpecs[commonv1.ReplicaType(v1xgboost.XGBoostReplicaTypeMaster)].Template
+	setPodConfig(job, &podTemplate, data)
+	if len(podTemplate.Spec.Volumes) != 0 || len(podTemplate.Spec.Containers[0].Env) != 0 {
+		t.Errorf("Got pod spec %v. Expected it unchanged", podTemplate.Spec)
+	}
+}
+
+func TestLightGBMConfig(t *testing.T) {
+	job := NewXGBoostJobWithMaster(2)
+	job.Spec.Framework = v1xgboost.FrameworkLightGBM
+
+	data, err := genConfigMapData(job, &lightGBMStrategy{})
+	if err != nil {
+		t.Fatalf("Failed to generate ConfigMap data: %v", err)
+	}
+	expectedMachineList := "test-xgboostjob-master-0 9999\ntest-xgboostjob-worker-0 9999\ntest-xgboostjob-worker-1 9999\n"
+	if data[machineListFileName] != expectedMachineList {
+		t.Errorf("Got machine list %q. Expected %q", data[machineListFileName], expectedMachineList)
+	}
+	expectedConfig := "num_machines = 3\nlocal_listen_port = 9999\nmachine_list_filename = /etc/xgboostjob/mlist.txt\n"
+	if data[lightGBMConfigFileName] != expectedConfig {
+		t.Errorf("Got config %q. Expected %q", data[lightGBMConfigFileName], expectedConfig)
+	}
+	if _, ok := data[paramsFileName]; ok {
+		t.Errorf("Got %s without xgbParams", paramsFileName)
+	}
+
+	r := &ReconcileXGBoostJob{strategies: newClusterSpecStrategies("")}
+	podTemplate := job.Spec.XGBReplicaSpecs[commonv1.ReplicaType(v1xgboost.XGBoostReplicaTypeWorker)].Template
+	if err := r.SetClusterSpec(job, &podTemplate, "worker", "0"); err != nil {
+		t.Fatalf("Failed to set cluster spec: %v", err)
+	}
+	envs := map[string]string{}
+	for _, env := range podTemplate.Spec.Containers[0].Env {
+		envs[env.Name] = env.Value
+	}
+	if envs[envMachineListFile] != "/etc/xgboostjob/mlist.txt" || envs[envLightGBMConfigFile] != "/etc/xgboostjob/lightgbm.conf" {
+		t.Errorf("Got env %v", envs)
+	}
+	if len(podTemplate.Spec.Volumes) != 1 {
+		t.Errorf("Got volumes %v. Expected the job ConfigMap", podTemplate.Spec.Volumes)
+	}
+}
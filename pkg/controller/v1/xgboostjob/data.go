@@ -0,0 +1,204 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xgboostjob
+
+import (
+	"path"
+	"strconv"
+	"strings"
+
+	v1xgboost "github.com/kubeflow/xgboost-operator/pkg/apis/xgboostjob/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// dataVolumeName is the name of the volume of the data claim, or of the data
+	// downloaded by the data download init container.
+	dataVolumeName = "xgboostjob-data"
+	// dataDownloadContainerName is the name of the data download init container.
+	dataDownloadContainerName = "xgboostjob-data-download"
+
+	// envDataDir is where the data claim is mounted, or where the data is downloaded to.
+	envDataDir = "XGBOOST_DATA_DIR"
+	// envDataURI is the object store prefix of the data.
+	envDataURI = "XGBOOST_DATA_URI"
+	// envDataFiles are the comma separated data files of the replica.
+	envDataFiles = "XGBOOST_DATA_FILES"
+	// envDataRowStart and envDataRowEnd bound the rows [start, end) of the replica.
+	envDataRowStart = "XGBOOST_DATA_ROW_START"
+	envDataRowEnd   = "XGBOOST_DATA_ROW_END"
+)
+
+// dataShard is the share of the data of a replica.
+type dataShard struct {
+	files []string
+	// rowStart and rowEnd bound the rows [rowStart, rowEnd) of the replica with the
+	// ByRowRange sharding.
+	rowStart, rowEnd int64
+}
+
+// computeDataShard returns the share of the data of the replica of the given rank. By
+// file, the files are dealt round-robin by rank. By row range, every replica gets all the
+// files and a contiguous range of their rows, the first ranks one more row than the
+// others if the rows cannot be split evenly.
+func computeDataShard(data *v1xgboost.DataSpec, rank int, worldSize int32) dataShard {
+	shard := dataShard{}
+	if data.Sharding == v1xgboost.ShardingModeByRowRange {
+		shard.files = data.Files
+		if data.Rows != nil && worldSize > 0 {
+			n, r := int64(worldSize), int64(rank)
+			size, extra := *data.Rows/n, *data.Rows%n
+			shard.rowStart = r*size + minInt64(r, extra)
+			shard.rowEnd = shard.rowStart + size
+			if r < extra {
+				shard.rowEnd++
+			}
+		}
+		return shard
+	}
+
+	for i, file := range data.Files {
+		if worldSize > 0 && int32(i)%worldSize == int32(rank) {
+			shard.files = append(shard.files, file)
+		}
+	}
+	return shard
+}
+
+// genDataEnv returns the environment variables telling a replica where its shard of the
+// data is, or nil if the job has no data.
+func genDataEnv(job *v1xgboost.XGBoostJob, cluster *ClusterSpec) []corev1.EnvVar {
+	data := job.Spec.Data
+	if data == nil {
+		return nil
+	}
+
+	var envs []corev1.EnvVar
+	var location string
+	switch {
+	case data.ClaimName != "":
+		location = data.Path
+		if location == "" {
+			location = v1xgboost.DefaultDataPath
+		}
+		envs = append(envs, corev1.EnvVar{Name: envDataDir, Value: location})
+	case data.Download != nil:
+		location = getDownloadDestination(data.Download)
+		envs = append(envs, corev1.EnvVar{Name: envDataDir, Value: location})
+	default:
+		location = data.URI
+		envs = append(envs, corev1.EnvVar{Name: envDataURI, Value: data.URI})
+	}
+	return append(envs, genShardEnv(data, location, cluster)...)
+}
+
+// genDownloadEnv returns the environment variables telling the data download init
+// container of a replica which files to download where.
+func genDownloadEnv(data *v1xgboost.DataSpec, cluster *ClusterSpec) []corev1.EnvVar {
+	envs := []corev1.EnvVar{
+		{Name: envDataURI, Value: data.URI},
+		{Name: envDataDir, Value: getDownloadDestination(data.Download)},
+	}
+	return append(envs, genShardEnv(data, data.URI, cluster)...)
+}
+
+// genShardEnv returns the environment variables of the shard of a replica, with its files
+// under location.
+func genShardEnv(data *v1xgboost.DataSpec, location string, cluster *ClusterSpec) []corev1.EnvVar {
+	var envs []corev1.EnvVar
+	shard := computeDataShard(data, cluster.Rank, cluster.WorldSize)
+	if len(shard.files) > 0 {
+		files := make([]string, 0, len(shard.files))
+		for _, file := range shard.files {
+			files = append(files, strings.TrimSuffix(location, "/")+"/"+path.Clean(file))
+		}
+		envs = append(envs, corev1.EnvVar{Name: envDataFiles, Value: strings.Join(files, ",")})
+	}
+	if data.Sharding == v1xgboost.ShardingModeByRowRange {
+		envs = append(envs,
+			corev1.EnvVar{Name: envDataRowStart, Value: strconv.FormatInt(shard.rowStart, 10)},
+			corev1.EnvVar{Name: envDataRowEnd, Value: strconv.FormatInt(shard.rowEnd, 10)})
+	}
+	return envs
+}
+
+// setPodData mounts the data claim read-only in every container of the pod, or the
+// emptyDir volume the data download init container downloads the shard of the pod to.
+func setPodData(job *v1xgboost.XGBoostJob, podTemplate *corev1.PodTemplateSpec, cluster *ClusterSpec) {
+	data := job.Spec.Data
+	if data == nil {
+		return
+	}
+
+	var mountPath string
+	switch {
+	case data.ClaimName != "":
+		mountPath = data.Path
+		if mountPath == "" {
+			mountPath = v1xgboost.DefaultDataPath
+		}
+		podTemplate.Spec.Volumes = append(podTemplate.Spec.Volumes, corev1.Volume{
+			Name: dataVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: data.ClaimName, ReadOnly: true},
+			},
+		})
+	case data.Download != nil:
+		mountPath = getDownloadDestination(data.Download)
+		podTemplate.Spec.Volumes = append(podTemplate.Spec.Volumes, corev1.Volume{
+			Name:         dataVolumeName,
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		})
+		download := corev1.Container{
+			Name:         dataDownloadContainerName,
+			Image:        data.Download.Image,
+			Env:          genDownloadEnv(data, cluster),
+			VolumeMounts: []corev1.VolumeMount{{Name: dataVolumeName, MountPath: mountPath}},
+		}
+		if data.Download.SecretName != "" {
+			download.EnvFrom = []corev1.EnvFromSource{{
+				SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: data.Download.SecretName}},
+			}}
+		}
+		// The init containers of the template may already need the data.
+		podTemplate.Spec.InitContainers = append([]corev1.Container{download}, podTemplate.Spec.InitContainers...)
+	default:
+		return
+	}
+
+	for i := range podTemplate.Spec.Containers {
+		podTemplate.Spec.Containers[i].VolumeMounts = append(podTemplate.Spec.Containers[i].VolumeMounts, corev1.VolumeMount{
+			Name:      dataVolumeName,
+			MountPath: mountPath,
+			ReadOnly:  true,
+		})
+	}
+}
+
+// getDownloadDestination returns where the data is downloaded to.
+func getDownloadDestination(download *v1xgboost.DataDownloadSpec) string {
+	if download.Destination == "" {
+		return v1xgboost.DefaultDataPath
+	}
+	return download.Destination
+}
+
+func minInt64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
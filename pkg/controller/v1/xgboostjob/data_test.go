@@ -0,0 +1,102 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xgboostjob
+
+import (
+	"reflect"
+	"testing"
+
+	commonv1 "github.com/kubeflow/common/pkg/apis/common/v1"
+	v1xgboost "github.com/kubeflow/xgboost-operator/pkg/apis/xgboostjob/v1"
+)
+
+func TestComputeDataShard(t *testing.T) {
+	rows := int64(10)
+	byFile := &v1xgboost.DataSpec{Files: []string{"a.csv", "b.csv", "c.csv", "d.csv", "e.csv"}, Sharding: v1xgboost.ShardingModeByFile}
+	byRowRange := &v1xgboost.DataSpec{Files: []string{"a.csv"}, Sharding: v1xgboost.ShardingModeByRowRange, Rows: &rows}
+
+	type tc struct {
+		data     *v1xgboost.DataSpec
+		rank     int
+		expected dataShard
+	}
+	testCase := []tc{
+		tc{data: byFile, rank: 0, expected: dataShard{files: []string{"a.csv", "d.csv"}}},
+		tc{data: byFile, rank: 1, expected: dataShard{files: []string{"b.csv", "e.csv"}}},
+		tc{data: byFile, rank: 2, expected: dataShard{files: []string{"c.csv"}}},
+		tc{data: byRowRange, rank: 0, expected: dataShard{files: []string{"a.csv"}, rowStart: 0, rowEnd: 4}},
+		tc{data: byRowRange, rank: 1, expected: dataShard{files: []string{"a.csv"}, rowStart: 4, rowEnd: 7}},
+		tc{data: byRowRange, rank: 2, expected: dataShard{files: []string{"a.csv"}, rowStart: 7, rowEnd: 10}},
+	}
+	for i, c := range testCase {
+		shard := computeDataShard(c.data, c.rank, 3)
+		if !reflect.DeepEqual(shard, c.expected) {
+			t.Errorf("Case %d: Got %+v. Expected %+v", i, shard, c.expected)
+		}
+	}
+}
+
+func TestDataEnv(t *testing.T) {
+	rows := int64(150)
+	onClaim := NewXGBoostJobWithMaster(1)
+	onClaim.Spec.Data = &v1xgboost.DataSpec{ClaimName: "iris", Files: []string{"iris-0.csv", "iris-1.csv"}}
+
+	inObjectStore := NewXGBoostJobWithMaster(1)
+	inObjectStore.Spec.Data = &v1xgboost.DataSpec{URI: "oss://datasets/iris/", Sharding: v1xgboost.ShardingModeByRowRange, Rows: &rows}
+
+	type tc struct {
+		job         *v1xgboost.XGBoostJob
+		rt          v1xgboost.XGBoostJobReplicaType
+		expectedEnv map[string]string
+	}
+	testCase := []tc{
+		tc{
+			job:         onClaim,
+			rt:          v1xgboost.XGBoostReplicaTypeWorker,
+			expectedEnv: map[string]string{envDataDir: v1xgboost.DefaultDataPath, envDataFiles: "/xgboostjob/data/iris-1.csv"},
+		},
+		tc{
+			job:         inObjectStore,
+			rt:          v1xgboost.XGBoostReplicaTypeMaster,
+			expectedEnv: map[string]string{envDataURI: "oss://datasets/iris/", envDataRowStart: "0", envDataRowEnd: "75"},
+		},
+	}
+	for i, c := range testCase {
+		podTemplate := c.job.Spec.XGBReplicaSpecs[commonv1.ReplicaType(c.rt)].Template.DeepCopy()
+		if err := SetPodEnv(c.job, podTemplate, string(c.rt), "0"); err != nil {
+			t.Fatalf("Case %d: failed to set pod env: %v", i, err)
+		}
+		cluster, err := newClusterSpec(c.job, string(c.rt), "0")
+		if err != nil {
+			t.Fatalf("Case %d: failed to get cluster spec: %v", i, err)
+		}
+		setPodData(c.job, podTemplate, cluster)
+
+		actual := map[string]string{}
+		for _, env := range podTemplate.Spec.Containers[0].Env {
+			actual[env.Name] = env.Value
+		}
+		for name, val := range c.expectedEnv {
+			if actual[name] != val {
+				t.Errorf("Case %d: for name %s Got %s. Expected %s", i, name, actual[name], val)
+			}
+		}
+		mounts := podTemplate.Spec.Containers[0].VolumeMounts
+		if hasMount := len(mounts) == 1 && mounts[0].ReadOnly; hasMount != (c.job.Spec.Data.ClaimName != "") {
+			t.Errorf("Case %d: Got volume mounts %v", i, mounts)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xgboostjob
+
+import (
+	"fmt"
+	"strings"
+
+	commonv1 "github.com/kubeflow/common/pkg/apis/common/v1"
+	v1xgboost "github.com/kubeflow/xgboost-operator/pkg/apis/xgboostjob/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+)
+
+const (
+	// dataDownloadFailedReason is the reason of the event of failed data downloads.
+	dataDownloadFailedReason = "DataDownloadFailed"
+	// dataDownloadedReason is the reason of the event of the data of all the replicas
+	// being downloaded.
+	dataDownloadedReason = "DataDownloaded"
+)
+
+// dataDownloadState is the state of the data download init container of a pod.
+type dataDownloadState int
+
+const (
+	dataDownloadUnknown dataDownloadState = iota
+	dataDownloadRunning
+	dataDownloadSucceeded
+	dataDownloadFailed
+)
+
+// getDataDownloadState returns the state of the data download of the pod, with the exit
+// code of the download if it failed. A download restarted by the kubelet after a failure
+// is failed until it succeeds.
+func getDataDownloadState(pod *corev1.Pod) (dataDownloadState, *corev1.ContainerStateTerminated) {
+	for _, status := range pod.Status.InitContainerStatuses {
+		if status.Name != dataDownloadContainerName {
+			continue
+		}
+		terminated := status.State.Terminated
+		switch {
+		case terminated != nil && terminated.ExitCode == 0:
+			return dataDownloadSucceeded, nil
+		case terminated != nil:
+			return dataDownloadFailed, terminated
+		case status.LastTerminationState.Terminated != nil && status.LastTerminationState.Terminated.ExitCode != 0:
+			return dataDownloadFailed, status.LastTerminationState.Terminated
+		default:
+			return dataDownloadRunning, nil
+		}
+	}
+	return dataDownloadUnknown, nil
+}
+
+// syncDataDownloads reports in the job status the progress of the data downloads of the
+// pods of the running job, with an event when some fail or when the data of all the
+// replicas is downloaded.
+func (r *ReconcileXGBoostJob) syncDataDownloads(xgboostJob *v1xgboost.XGBoostJob) error {
+	if xgboostJob.Spec.Data == nil || xgboostJob.Spec.Data.Download == nil || isFinished(xgboostJob) {
+		return nil
+	}
+
+	pods, err := r.GetPodsForJob(xgboostJob)
+	if err != nil {
+		return err
+	}
+	statuses := map[commonv1.ReplicaType]*v1xgboost.DataDownloadStatus{}
+	failures := map[commonv1.ReplicaType][]string{}
+	for _, pod := range pods {
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		rtype, ok := getReplicaType(xgboostJob, pod)
+		if !ok {
+			continue
+		}
+		state, terminated := getDataDownloadState(pod)
+		if state == dataDownloadUnknown {
+			continue
+		}
+		status := statuses[rtype]
+		if status == nil {
+			status = &v1xgboost.DataDownloadStatus{}
+			statuses[rtype] = status
+		}
+		switch state {
+		case dataDownloadRunning:
+			status.Downloading++
+		case dataDownloadSucceeded:
+			status.Downloaded++
+		case dataDownloadFailed:
+			status.Failed++
+			failure := fmt.Sprintf("pod %s exited with code %d", pod.Name, terminated.ExitCode)
+			if terminated.Message != "" {
+				failure += ": " + strings.TrimSpace(terminated.Message)
+			}
+			failures[rtype] = append(failures[rtype], failure)
+		}
+	}
+	if len(statuses) == 0 {
+		statuses = nil
+	}
+	previous := xgboostJob.Status.DataDownloads
+	if apiequality.Semantic.DeepEqual(statuses, previous) {
+		return nil
+	}
+
+	modified := xgboostJob.DeepCopy()
+	modified.Status.DataDownloads = statuses
+	if err := r.patchStatus(xgboostJob, modified); err != nil {
+		return err
+	}
+
+	var downloaded, previouslyDownloaded int32
+	for rtype, status := range statuses {
+		downloaded += status.Downloaded
+		if old := previous[rtype]; old != nil {
+			previouslyDownloaded += old.Downloaded
+			if status.Failed <= old.Failed {
+				continue
+			}
+		}
+		if status.Failed > 0 {
+			msg := fmt.Sprintf("XGBoostJob %s failed to download the data of %d %s replica(s): %s.",
+				xgboostJob.Name, status.Failed, rtype, strings.Join(failures[rtype], "; "))
+			r.Recorder.Event(xgboostJob, corev1.EventTypeWarning, dataDownloadFailedReason, msg)
+		}
+	}
+	if total := computeTotalReplicas(xgboostJob); downloaded == total && previouslyDownloaded < total {
+		msg := fmt.Sprintf("XGBoostJob %s downloaded the data of all its %d replicas.", xgboostJob.Name, total)
+		r.Recorder.Event(xgboostJob, corev1.EventTypeNormal, dataDownloadedReason, msg)
+	}
+	*xgboostJob = *modified
+	return nil
+}
+
+// getReplicaType returns the replica type of the job the pod belongs to.
+func getReplicaType(xgboostJob *v1xgboost.XGBoostJob, pod *corev1.Pod) (commonv1.ReplicaType, bool) {
+	label := pod.Labels[commonv1.ReplicaTypeLabel]
+	for rtype := range xgboostJob.Spec.XGBReplicaSpecs {
+		if strings.ToLower(string(rtype)) == label {
+			return rtype, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,153 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xgboostjob
+
+import (
+	"strings"
+	"testing"
+
+	commonv1 "github.com/kubeflow/common/pkg/apis/common/v1"
+	v1xgboost "github.com/kubeflow/xgboost-operator/pkg/apis/xgboostjob/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// newDownloadJob returns a job with the Master and worker Workers downloading their data.
+func newDownloadJob(worker int) *v1xgboost.XGBoostJob {
+	job := NewXGBoostJobWithMaster(worker)
+	job.Spec.Data = &v1xgboost.DataSpec{
+		URI:      "oss://datasets/iris",
+		Files:    []string{"iris-0.csv", "iris-1.csv"},
+		Sharding: v1xgboost.ShardingModeByFile,
+		Download: &v1xgboost.DataDownloadSpec{Image: "downloader", SecretName: "oss-credentials"},
+	}
+	return job
+}
+
+func TestPodDataDownload(t *testing.T) {
+	job := newDownloadJob(1)
+	podTemplate := job.Spec.XGBReplicaSpecs[commonv1.ReplicaType(v1xgboost.XGBoostReplicaTypeWorker)].Template.DeepCopy()
+	podTemplate.Spec.InitContainers = []corev1.Container{{Name: "prepare"}}
+	if err := SetPodEnv(job, podTemplate, "worker", "0"); err != nil {
+		t.Fatalf("Failed to set pod env: %v", err)
+	}
+	cluster, err := newClusterSpec(job, "worker", "0")
+	if err != nil {
+		t.Fatalf("Failed to get cluster spec: %v", err)
+	}
+	setPodData(job, podTemplate, cluster)
+
+	if len(podTemplate.Spec.InitContainers) != 2 || podTemplate.Spec.InitContainers[0].Name != dataDownloadContainerName {
+		t.Fatalf("Got init containers %v. Expected the data download first", podTemplate.Spec.InitContainers)
+	}
+	download := podTemplate.Spec.InitContainers[0]
+	if download.Image != "downloader" || len(download.EnvFrom) != 1 || download.EnvFrom[0].SecretRef.Name != "oss-credentials" {
+		t.Errorf("Got data download %v", download)
+	}
+	if len(podTemplate.Spec.Volumes) != 1 || podTemplate.Spec.Volumes[0].EmptyDir == nil {
+		t.Errorf("Got volumes %v. Expected an emptyDir", podTemplate.Spec.Volumes)
+	}
+
+	type tc struct {
+		container   corev1.Container
+		expectedEnv map[string]string
+	}
+	testCase := []tc{
+		tc{
+			container:   download,
+			expectedEnv: map[string]string{envDataURI: "oss://datasets/iris", envDataDir: v1xgboost.DefaultDataPath, envDataFiles: "oss://datasets/iris/iris-1.csv"},
+		},
+		tc{
+			container:   podTemplate.Spec.Containers[0],
+			expectedEnv: map[string]string{envDataURI: "", envDataDir: v1xgboost.DefaultDataPath, envDataFiles: "/xgboostjob/data/iris-1.csv"},
+		},
+	}
+	for i, c := range testCase {
+		actual := map[string]string{}
+		for _, env := range c.container.Env {
+			actual[env.Name] = env.Value
+		}
+		for name, val := range c.expectedEnv {
+			if actual[name] != val {
+				t.Errorf("Case %d: for name %s Got %s. Expected %s", i, name, actual[name], val)
+			}
+		}
+		if len(c.container.VolumeMounts) != 1 || c.container.VolumeMounts[0].MountPath != v1xgboost.DefaultDataPath {
+			t.Errorf("Case %d: Got volume mounts %v", i, c.container.VolumeMounts)
+		}
+	}
+}
+
+func TestSyncDataDownloads(t *testing.T) {
+	s := runtime.NewScheme()
+	if err := scheme.AddToScheme(s); err != nil {
+		t.Fatalf("Failed to build the scheme: %v", err)
+	}
+	if err := v1xgboost.AddToScheme(s); err != nil {
+		t.Fatalf("Failed to build the scheme: %v", err)
+	}
+
+	job := newDownloadJob(2)
+	r := &ReconcileXGBoostJob{}
+	r.JobController.Controller = r
+	recorder := record.NewFakeRecorder(10)
+	r.Recorder = recorder
+	newPod := func(rtype v1xgboost.XGBoostJobReplicaType, index int, state corev1.ContainerState) runtime.Object {
+		pod := NewXGBoostPod(job, rtype, index)
+		pod.Status.InitContainerStatuses = []corev1.ContainerStatus{{Name: dataDownloadContainerName, State: state}}
+		return pod
+	}
+	running := corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}
+	succeeded := corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 0}}
+	failed := corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 1, Message: "access denied\n"}}
+
+	r.Client = fake.NewFakeClientWithScheme(s, job.DeepCopy(),
+		newPod(v1xgboost.XGBoostReplicaTypeMaster, 0, succeeded),
+		newPod(v1xgboost.XGBoostReplicaTypeWorker, 0, running),
+		newPod(v1xgboost.XGBoostReplicaTypeWorker, 1, failed))
+	if err := r.syncDataDownloads(job); err != nil {
+		t.Fatalf("Failed to sync data downloads: %v", err)
+	}
+	master := job.Status.DataDownloads[commonv1.ReplicaType(v1xgboost.XGBoostReplicaTypeMaster)]
+	worker := job.Status.DataDownloads[commonv1.ReplicaType(v1xgboost.XGBoostReplicaTypeWorker)]
+	if master == nil || master.Downloaded != 1 || worker == nil || worker.Downloading != 1 || worker.Failed != 1 {
+		t.Fatalf("Got data downloads %v and %v", master, worker)
+	}
+	if event := <-recorder.Events; !strings.Contains(event, dataDownloadFailedReason) || !strings.Contains(event, "test-xgboostjob-worker-1 exited with code 1: access denied") {
+		t.Errorf("Got event %q. Expected a data download failure", event)
+	}
+
+	// The failure is only reported once, and the end of the downloads once.
+	r.Client = fake.NewFakeClientWithScheme(s, job.DeepCopy(),
+		newPod(v1xgboost.XGBoostReplicaTypeMaster, 0, succeeded),
+		newPod(v1xgboost.XGBoostReplicaTypeWorker, 0, succeeded),
+		newPod(v1xgboost.XGBoostReplicaTypeWorker, 1, succeeded))
+	if err := r.syncDataDownloads(job); err != nil {
+		t.Fatalf("Failed to sync data downloads: %v", err)
+	}
+	if event := <-recorder.Events; !strings.Contains(event, dataDownloadedReason) {
+		t.Errorf("Got event %q. Expected the data to be downloaded", event)
+	}
+	if err := r.syncDataDownloads(job); err != nil {
+		t.Fatalf("Failed to sync data downloads: %v", err)
+	}
+	if len(recorder.Events) != 0 {
+		t.Errorf("Got event %q. Expected none", <-recorder.Events)
+	}
+}
@@ -0,0 +1,287 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xgboostjob
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	commonv1 "github.com/kubeflow/common/pkg/apis/common/v1"
+	commonutil "github.com/kubeflow/common/pkg/util"
+	v1xgboost "github.com/kubeflow/xgboost-operator/pkg/apis/xgboostjob/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Rules classifying the failure of a pod, in the order they are tried.
+const (
+	// failureRuleEvicted: the pod was evicted from its node, e.g. under node pressure.
+	failureRuleEvicted = "Evicted"
+	// failureRuleOOMKilled: the container went over its memory limit. It may not again,
+	// e.g. if the kernel killed it under node memory pressure, the restarts are bounded by
+	// the BackoffLimit of the job.
+	failureRuleOOMKilled = "OOMKilled"
+	// failureRulePermanentExitCode: exit codes 1-127 are errors of the program.
+	failureRulePermanentExitCode = "PermanentExitCode"
+	// failureRuleRetryableExitCode: exit codes 128 and above are signals, e.g. 137 for SIGKILL.
+	failureRuleRetryableExitCode = "RetryableExitCode"
+	// failureRuleDataDownloadFailed: the data download init container of the pod failed.
+	failureRuleDataDownloadFailed = "DataDownloadFailed"
+	// failureRuleNoExitCode: the pod failed before the container terminated, or although
+	// it exited with code 0, e.g. because another container failed.
+	failureRuleNoExitCode = "NoExitCode"
+
+	// podReasonEvicted is the reason of the status of an evicted pod.
+	podReasonEvicted = "Evicted"
+	// containerReasonOOMKilled is the reason of the state of an OOM killed container.
+	containerReasonOOMKilled = "OOMKilled"
+)
+
+// podFailure is the classification of a failed pod.
+type podFailure struct {
+	podName string
+	// exitCode is the exit code of the xgboostjob container, or -1 if it did not terminate.
+	exitCode  int32
+	retryable bool
+	// rule is the rule that classified the failure.
+	rule string
+}
+
+func (f podFailure) String() string {
+	class := "permanent"
+	if f.retryable {
+		class = "retryable"
+	}
+	var what string
+	switch f.rule {
+	case failureRuleEvicted:
+		what = "was evicted"
+	case failureRuleOOMKilled:
+		what = "was OOMKilled"
+	case failureRuleDataDownloadFailed:
+		what = fmt.Sprintf("failed to download its data with code %d", f.exitCode)
+	case failureRuleNoExitCode:
+		if f.exitCode < 0 {
+			what = "failed before its container terminated"
+		} else {
+			what = fmt.Sprintf("failed although its container exited with code %d", f.exitCode)
+		}
+	default:
+		what = fmt.Sprintf("exited with code %d", f.exitCode)
+	}
+	return fmt.Sprintf("pod %s %s, %s by rule %s", f.podName, what, class, f.rule)
+}
+
+// classifyPodFailure decides whether the failure of a pod is worth a restart.
+func classifyPodFailure(pod *corev1.Pod) podFailure {
+	failure := podFailure{podName: pod.Name, exitCode: -1}
+	var oomKilled bool
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == v1xgboost.DefaultContainerName && status.State.Terminated != nil {
+			failure.exitCode = status.State.Terminated.ExitCode
+			oomKilled = status.State.Terminated.Reason == containerReasonOOMKilled
+		}
+	}
+
+	_, downloadFailed := getDataDownloadState(pod)
+
+	switch {
+	case pod.Status.Reason == podReasonEvicted:
+		failure.retryable, failure.rule = true, failureRuleEvicted
+	case oomKilled:
+		failure.retryable, failure.rule = true, failureRuleOOMKilled
+	case failure.exitCode >= 128:
+		failure.retryable, failure.rule = true, failureRuleRetryableExitCode
+	case failure.exitCode > 0:
+		failure.retryable, failure.rule = false, failureRulePermanentExitCode
+	case downloadFailed != nil:
+		failure.exitCode = downloadFailed.ExitCode
+		failure.retryable, failure.rule = false, failureRuleDataDownloadFailed
+	default:
+		failure.retryable, failure.rule = false, failureRuleNoExitCode
+	}
+	return failure
+}
+
+// getPodFailures classifies the failed pods of the replica type rtype.
+func (r *ReconcileXGBoostJob) getPodFailures(xgboostJob *v1xgboost.XGBoostJob, rtype commonv1.ReplicaType) ([]podFailure, []*corev1.Pod, error) {
+	pods, err := r.GetPodsForJob(xgboostJob)
+	if err != nil {
+		return nil, nil, err
+	}
+	pods, err = r.FilterPodsForReplicaType(pods, strings.ToLower(string(rtype)))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var failures []podFailure
+	var failedPods []*corev1.Pod
+	for _, pod := range pods {
+		if pod.Status.Phase != corev1.PodFailed {
+			continue
+		}
+		// The pods torn down by a restart of the whole job fail on their way out.
+		if isJobRestartScope(xgboostJob) && (pod.DeletionTimestamp != nil || !isCurrentAttempt(xgboostJob, pod)) {
+			continue
+		}
+		failures = append(failures, classifyPodFailure(pod))
+		failedPods = append(failedPods, pod)
+	}
+	return failures, failedPods, nil
+}
+
+// ReconcilePods reconciles the pods of the replica type rtype with the common job
+// controller. With the ExitCode restart policy, it would delete the failed pods with an
+// exit code of 128 and above before their failure is classified, e.g. OOMKilled pods,
+// and keep the evicted ones. It is given the Never restart policy instead, which creates
+// the same pods, and the failed pods are restarted according to their classification
+// by UpdateJobStatus.
+func (r *ReconcileXGBoostJob) ReconcilePods(job interface{}, jobStatus *commonv1.JobStatus, pods []*corev1.Pod,
+	rtype commonv1.ReplicaType, spec *commonv1.ReplicaSpec, replicas map[commonv1.ReplicaType]*commonv1.ReplicaSpec) error {
+	if spec.RestartPolicy == commonv1.RestartPolicyExitCode {
+		spec = spec.DeepCopy()
+		spec.RestartPolicy = commonv1.RestartPolicyNever
+	}
+	return r.JobController.ReconcilePods(job, jobStatus, pods, rtype, spec, replicas)
+}
+
+// restartRetryablePods deletes the failed pods classified as retryable so that they are
+// created again.
+func (r *ReconcileXGBoostJob) restartRetryablePods(xgboostJob *v1xgboost.XGBoostJob, failures []podFailure, pods []*corev1.Pod) error {
+	for i, pod := range pods {
+		if !failures[i].retryable || pod.DeletionTimestamp != nil {
+			continue
+		}
+		if err := r.PodControl.DeletePod(pod.Namespace, pod.Name, xgboostJob); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isJobRestartScope returns true if all the pods of the job are restarted together.
+func isJobRestartScope(xgboostJob *v1xgboost.XGBoostJob) bool {
+	return xgboostJob.Spec.RestartScope == v1xgboost.RestartScopeJob
+}
+
+// isCurrentAttempt returns true if the pod belongs to the current attempt of the job.
+// Pods without attempt label belong to the first one.
+func isCurrentAttempt(xgboostJob *v1xgboost.XGBoostJob, pod *corev1.Pod) bool {
+	attempt := "0"
+	if label, ok := pod.Labels[labelXGBoostJobAttempt]; ok {
+		attempt = label
+	}
+	return attempt == strconv.Itoa(int(xgboostJob.Status.Attempt))
+}
+
+// restartJob restarts all the pods of the job together in a new attempt, after failures
+// of the replica type rtype. The new attempt is written before any pod is deleted, with
+// the resourceVersion of the job, so that a job read from a stale cache is never
+// restarted twice. The job is then the one written.
+func (r *ReconcileXGBoostJob) restartJob(xgboostJob *v1xgboost.XGBoostJob, rtype commonv1.ReplicaType, failures []podFailure) error {
+	modified := xgboostJob.DeepCopy()
+	modified.Status.Attempt++
+	if modified.Status.RestartCounts == nil {
+		modified.Status.RestartCounts = map[commonv1.ReplicaType]int32{}
+	}
+	modified.Status.RestartCounts[rtype] += int32(len(failures))
+	if err := r.patchStatus(xgboostJob, modified); err != nil {
+		return err
+	}
+	*xgboostJob = *modified
+
+	pods, err := r.GetPodsForJob(xgboostJob)
+	if err != nil {
+		return err
+	}
+	for _, pod := range pods {
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		if err := r.PodControl.DeletePod(pod.Namespace, pod.Name, xgboostJob); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isRetryable returns true if all the failures are retryable.
+func isRetryable(failures []podFailure) bool {
+	for _, failure := range failures {
+		if !failure.retryable {
+			return false
+		}
+	}
+	return true
+}
+
+// describeFailures joins the description of the failures.
+func describeFailures(failures []podFailure) string {
+	descriptions := make([]string, 0, len(failures))
+	for _, failure := range failures {
+		descriptions = append(descriptions, failure.String())
+	}
+	return strings.Join(descriptions, "; ")
+}
+
+// computeRestartCounts returns the restart counts of the job once jobStatus is written.
+// Unless the job fails, the failed pods of the replica types with the ExitCode restart
+// policy are restarted, so every new failed pod of them is a restart. The job carries the
+// status read from the API server, and jobStatus the one reconciled from its pods.
+func computeRestartCounts(xgboostJob *v1xgboost.XGBoostJob, jobStatus *commonv1.JobStatus) map[commonv1.ReplicaType]int32 {
+	counts := make(map[commonv1.ReplicaType]int32, len(xgboostJob.Status.RestartCounts))
+	for rtype, count := range xgboostJob.Status.RestartCounts {
+		counts[rtype] = count
+	}
+	// The restarts of the whole job are counted by restartJob.
+	if !commonutil.IsFailed(*jobStatus) && !isJobRestartScope(xgboostJob) {
+		for rtype, spec := range xgboostJob.Spec.XGBReplicaSpecs {
+			status := jobStatus.ReplicaStatuses[rtype]
+			if spec == nil || spec.RestartPolicy != commonv1.RestartPolicyExitCode || status == nil {
+				continue
+			}
+			var previous int32
+			if old := xgboostJob.Status.ReplicaStatuses[rtype]; old != nil {
+				previous = old.Failed
+			}
+			if status.Failed > previous {
+				counts[rtype] += status.Failed - previous
+			}
+		}
+	}
+	if len(counts) == 0 {
+		return nil
+	}
+	return counts
+}
+
+// isPastBackoffLimit returns true if restarting the failed pods of jobStatus would
+// restart the pods of the job more times than its BackoffLimit. With the Job restart
+// scope, the BackoffLimit bounds the number of attempts instead.
+func isPastBackoffLimit(xgboostJob *v1xgboost.XGBoostJob, jobStatus *commonv1.JobStatus) bool {
+	backoffLimit := xgboostJob.Spec.RunPolicy.BackoffLimit
+	if backoffLimit == nil {
+		return false
+	}
+	if isJobRestartScope(xgboostJob) {
+		return xgboostJob.Status.Attempt+1 > *backoffLimit
+	}
+	var restarts int32
+	for _, count := range computeRestartCounts(xgboostJob, jobStatus) {
+		restarts += count
+	}
+	return restarts > *backoffLimit
+}
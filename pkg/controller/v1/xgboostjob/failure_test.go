@@ -0,0 +1,362 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xgboostjob
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	commonv1 "github.com/kubeflow/common/pkg/apis/common/v1"
+	commonutil "github.com/kubeflow/common/pkg/util"
+	v1xgboost "github.com/kubeflow/xgboost-operator/pkg/apis/xgboostjob/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// newFailedPod returns a failed pod whose xgboostjob container exited with exitCode.
+func newFailedPod(exitCode int32, reason string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-xgboostjob-worker-0"},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodFailed,
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name: v1xgboost.DefaultContainerName,
+					State: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{ExitCode: exitCode, Reason: reason},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestClassifyPodFailure(t *testing.T) {
+	evicted := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-xgboostjob-worker-0"},
+		Status:     corev1.PodStatus{Phase: corev1.PodFailed, Reason: podReasonEvicted},
+	}
+	noExitCode := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-xgboostjob-worker-0"},
+		Status:     corev1.PodStatus{Phase: corev1.PodFailed},
+	}
+
+	downloadFailed := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-xgboostjob-worker-0"},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodFailed,
+			InitContainerStatuses: []corev1.ContainerStatus{{
+				Name:  dataDownloadContainerName,
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 1}},
+			}},
+		},
+	}
+
+	type tc struct {
+		pod       *corev1.Pod
+		retryable bool
+		rule      string
+	}
+	testCase := []tc{
+		tc{pod: newFailedPod(1, "Error"), retryable: false, rule: failureRulePermanentExitCode},
+		tc{pod: newFailedPod(127, "Error"), retryable: false, rule: failureRulePermanentExitCode},
+		tc{pod: newFailedPod(128, "Error"), retryable: true, rule: failureRuleRetryableExitCode},
+		tc{pod: newFailedPod(143, "Error"), retryable: true, rule: failureRuleRetryableExitCode},
+		tc{pod: newFailedPod(137, containerReasonOOMKilled), retryable: true, rule: failureRuleOOMKilled},
+		tc{pod: evicted, retryable: true, rule: failureRuleEvicted},
+		tc{pod: downloadFailed, retryable: false, rule: failureRuleDataDownloadFailed},
+		tc{pod: noExitCode, retryable: false, rule: failureRuleNoExitCode},
+		tc{pod: newFailedPod(0, "Completed"), retryable: false, rule: failureRuleNoExitCode},
+	}
+	for i, c := range testCase {
+		failure := classifyPodFailure(c.pod)
+		if failure.retryable != c.retryable || failure.rule != c.rule {
+			t.Errorf("Case %d: Got %v. Expected retryable %v by rule %s", i, failure, c.retryable, c.rule)
+		}
+	}
+
+	failures := []podFailure{classifyPodFailure(newFailedPod(1, "Error")), classifyPodFailure(evicted)}
+	if isRetryable(failures) {
+		t.Errorf("Got retryable failures %v. Expected a permanent one", failures)
+	}
+	expected := "pod test-xgboostjob-worker-0 exited with code 1, permanent by rule PermanentExitCode; " +
+		"pod test-xgboostjob-worker-0 was evicted, retryable by rule Evicted"
+	if actual := describeFailures(failures); actual != expected {
+		t.Errorf("Got %q. Expected %q", actual, expected)
+	}
+	expected = "pod test-xgboostjob-worker-0 failed before its container terminated, permanent by rule NoExitCode; " +
+		"pod test-xgboostjob-worker-0 failed although its container exited with code 0, permanent by rule NoExitCode"
+	failures = []podFailure{classifyPodFailure(noExitCode), classifyPodFailure(newFailedPod(0, "Completed"))}
+	if actual := describeFailures(failures); actual != expected {
+		t.Errorf("Got %q. Expected %q", actual, expected)
+	}
+}
+
+func TestReconcilePodFailures(t *testing.T) {
+	evicted := corev1.PodStatus{Phase: corev1.PodFailed, Reason: podReasonEvicted}
+
+	type tc struct {
+		status          corev1.PodStatus
+		expectedDeleted bool
+		expectedReason  string
+		expectedRule    string
+	}
+	testCase := []tc{
+		// Killed with code 137, the OOMKilled pod is restarted by its own rule.
+		tc{status: newFailedPod(137, containerReasonOOMKilled).Status, expectedDeleted: true,
+			expectedReason: xgboostJobRestartingReason, expectedRule: failureRuleOOMKilled},
+		tc{status: newFailedPod(143, "Error").Status, expectedDeleted: true,
+			expectedReason: xgboostJobRestartingReason, expectedRule: failureRuleRetryableExitCode},
+		tc{status: newFailedPod(1, "Error").Status, expectedDeleted: false,
+			expectedReason: xgboostJobFailedReason, expectedRule: failureRulePermanentExitCode},
+		tc{status: evicted, expectedDeleted: true,
+			expectedReason: xgboostJobRestartingReason, expectedRule: failureRuleEvicted},
+	}
+	for i, c := range testCase {
+		job := NewXGBoostJobWithMaster(1)
+		job.Spec.XGBReplicaSpecs[commonv1.ReplicaType(v1xgboost.XGBoostReplicaTypeWorker)].RestartPolicy = commonv1.RestartPolicyExitCode
+		master := NewXGBoostPod(job, v1xgboost.XGBoostReplicaTypeMaster, 0)
+		master.Status.Phase = corev1.PodRunning
+		worker := NewXGBoostPod(job, v1xgboost.XGBoostReplicaTypeWorker, 0)
+		worker.Status = c.status
+		r, podControl := newTestReconciler(t, job, master, worker,
+			&corev1.Service{ObjectMeta: master.ObjectMeta}, &corev1.Service{ObjectMeta: worker.ObjectMeta})
+
+		latest := reconcileTestJob(t, r, job)
+		if deleted := len(podControl.DeletePodName) > 0; deleted != c.expectedDeleted || len(podControl.DeletePodName) > 1 {
+			t.Errorf("Case %d: Got deleted pods %v. Expected deleted %v once", i, podControl.DeletePodName, c.expectedDeleted)
+		}
+		if failed := commonutil.IsFailed(latest.Status.JobStatus); failed != (c.expectedReason == xgboostJobFailedReason) {
+			t.Errorf("Case %d: Got failed %v. Expected the reason %s", i, failed, c.expectedReason)
+		}
+		var found bool
+		for events := r.recorder.(*record.FakeRecorder).Events; len(events) > 0 && !found; {
+			event := <-events
+			found = strings.Contains(event, c.expectedReason) && strings.Contains(event, "by rule "+c.expectedRule)
+		}
+		if !found {
+			t.Errorf("Case %d: Got no %s event by rule %s", i, c.expectedReason, c.expectedRule)
+		}
+	}
+}
+
+func TestRestartCounts(t *testing.T) {
+	worker := commonv1.ReplicaType(v1xgboost.XGBoostReplicaTypeWorker)
+	backoffLimit := int32(2)
+
+	newJob := func(restarts, failed int32) *v1xgboost.XGBoostJob {
+		job := NewXGBoostJobWithMaster(2)
+		job.Spec.RunPolicy.BackoffLimit = &backoffLimit
+		job.Spec.XGBReplicaSpecs[worker].RestartPolicy = commonv1.RestartPolicyExitCode
+		job.Status.ReplicaStatuses = map[commonv1.ReplicaType]*commonv1.ReplicaStatus{worker: {Failed: failed}}
+		if restarts > 0 {
+			job.Status.RestartCounts = map[commonv1.ReplicaType]int32{worker: restarts}
+		}
+		return job
+	}
+	newStatus := func(failed int32) *commonv1.JobStatus {
+		return &commonv1.JobStatus{
+			ReplicaStatuses: map[commonv1.ReplicaType]*commonv1.ReplicaStatus{worker: {Failed: failed}},
+		}
+	}
+	failedStatus := newStatus(2)
+	commonutil.UpdateJobConditions(failedStatus, commonv1.JobFailed, xgboostJobFailedReason, "")
+
+	neverRestart := newJob(0, 0)
+	neverRestart.Spec.XGBReplicaSpecs[worker].RestartPolicy = commonv1.RestartPolicyNever
+
+	type tc struct {
+		job                 *v1xgboost.XGBoostJob
+		status              *commonv1.JobStatus
+		expectedRestarts    int32
+		expectedPastBackoff bool
+	}
+	testCase := []tc{
+		tc{job: newJob(0, 0), status: newStatus(0), expectedRestarts: 0, expectedPastBackoff: false},
+		tc{job: newJob(0, 0), status: newStatus(1), expectedRestarts: 1, expectedPastBackoff: false},
+		// The failed pod is still there while it is restarted.
+		tc{job: newJob(1, 1), status: newStatus(1), expectedRestarts: 1, expectedPastBackoff: false},
+		tc{job: newJob(1, 0), status: newStatus(2), expectedRestarts: 3, expectedPastBackoff: true},
+		tc{job: newJob(2, 0), status: failedStatus, expectedRestarts: 2, expectedPastBackoff: false},
+		tc{job: neverRestart, status: newStatus(1), expectedRestarts: 0, expectedPastBackoff: false},
+	}
+	for i, c := range testCase {
+		counts := computeRestartCounts(c.job, c.status)
+		if counts[worker] != c.expectedRestarts {
+			t.Errorf("Case %d: Got %d restarts. Expected %d", i, counts[worker], c.expectedRestarts)
+		}
+		if actual := isPastBackoffLimit(c.job, c.status); actual != c.expectedPastBackoff {
+			t.Errorf("Case %d: Got past backoff limit %v. Expected %v", i, actual, c.expectedPastBackoff)
+		}
+	}
+
+	// A crash looping worker is restarted until it reaches the backoff limit.
+	job := newJob(0, 0)
+	job.Status.ReplicaStatuses = nil
+	master := NewXGBoostPod(job, v1xgboost.XGBoostReplicaTypeMaster, 0)
+	master.Status.Phase = corev1.PodRunning
+	pod := NewXGBoostPod(job, v1xgboost.XGBoostReplicaTypeWorker, 0)
+	r, _ := newTestReconciler(t, job, master, pod, &corev1.Service{ObjectMeta: master.ObjectMeta}, &corev1.Service{ObjectMeta: pod.ObjectMeta})
+	for restarts := int32(1); restarts <= backoffLimit+1; restarts++ {
+		pod.Status = newFailedPod(143, "Error").Status
+		if err := r.Update(context.Background(), pod); err != nil {
+			t.Fatalf("Failed to update the worker pod: %v", err)
+		}
+		latest := reconcileTestJob(t, r, job)
+		// The failure past the backoff limit fails the job instead of restarting it.
+		expectedRestarts, pastBackoff := restarts, restarts > backoffLimit
+		if pastBackoff {
+			expectedRestarts = backoffLimit
+		}
+		if latest.Status.RestartCounts[worker] != expectedRestarts || commonutil.IsFailed(latest.Status.JobStatus) != pastBackoff {
+			t.Errorf("Got %d restarts and conditions %v. Expected %d restarts and failed %v",
+				latest.Status.RestartCounts[worker], latest.Status.Conditions, expectedRestarts, pastBackoff)
+		}
+
+		// The worker is created again.
+		pod.Status = corev1.PodStatus{Phase: corev1.PodRunning}
+		if err := r.Update(context.Background(), pod); err != nil {
+			t.Fatalf("Failed to update the worker pod: %v", err)
+		}
+		reconcileTestJob(t, r, job)
+	}
+	latest := reconcileTestJob(t, r, job)
+	condition := latest.Status.Conditions[len(latest.Status.Conditions)-1]
+	if condition.Type != commonv1.JobFailed || condition.Reason != xgboostJobBackoffLimitExceededReason {
+		t.Errorf("Got condition %v. Expected the job failed with reason %s", condition, xgboostJobBackoffLimitExceededReason)
+	}
+}
+
+func TestJobRestartScope(t *testing.T) {
+	backoffLimit := int32(2)
+	job := NewXGBoostJobWithMaster(3)
+	job.Spec.RestartScope = v1xgboost.RestartScopeJob
+	job.Spec.RunPolicy.BackoffLimit = &backoffLimit
+	job.Status.Attempt = 1
+	job.Status.RestartCounts = map[commonv1.ReplicaType]int32{commonv1.ReplicaType(v1xgboost.XGBoostReplicaTypeWorker): 1}
+
+	r := &ReconcileXGBoostJob{}
+	r.JobController.Controller = r
+	newPod := func(index int, attempt string, terminating bool) runtime.Object {
+		pod := NewXGBoostPod(job, v1xgboost.XGBoostReplicaTypeWorker, index)
+		pod.Status = newFailedPod(137, "Error").Status
+		pod.Labels[labelXGBoostJobAttempt] = attempt
+		if terminating {
+			now := metav1.Now()
+			pod.DeletionTimestamp = &now
+		}
+		return pod
+	}
+	// Only worker-1 failed in the current attempt, the others are torn down.
+	r.Client = fake.NewFakeClientWithScheme(scheme.Scheme, newPod(0, "0", false), newPod(1, "1", false), newPod(2, "1", true))
+
+	failures, _, err := r.getPodFailures(job, commonv1.ReplicaType(v1xgboost.XGBoostReplicaTypeWorker))
+	if err != nil {
+		t.Fatalf("Failed to get pod failures: %v", err)
+	}
+	if len(failures) != 1 || failures[0].podName != "test-xgboostjob-worker-1" {
+		t.Errorf("Got failures %v. Expected test-xgboostjob-worker-1 only", failures)
+	}
+
+	status := &commonv1.JobStatus{ReplicaStatuses: map[commonv1.ReplicaType]*commonv1.ReplicaStatus{
+		commonv1.ReplicaType(v1xgboost.XGBoostReplicaTypeWorker): {Failed: 3},
+	}}
+	if counts := computeRestartCounts(job, status); !reflect.DeepEqual(counts, job.Status.RestartCounts) {
+		t.Errorf("Got restart counts %v. Expected %v", counts, job.Status.RestartCounts)
+	}
+	if isPastBackoffLimit(job, status) {
+		t.Errorf("Got past backoff limit in attempt %d. Expected a new attempt", job.Status.Attempt)
+	}
+	job.Status.Attempt = 2
+	if !isPastBackoffLimit(job, status) {
+		t.Errorf("Got a new attempt after attempt %d. Expected past backoff limit", job.Status.Attempt)
+	}
+}
+
+func TestRestartJob(t *testing.T) {
+	job := NewXGBoostJobWithMaster(2)
+	job.Spec.RestartScope = v1xgboost.RestartScopeJob
+	job.Spec.XGBReplicaSpecs[commonv1.ReplicaType(v1xgboost.XGBoostReplicaTypeWorker)].RestartPolicy = commonv1.RestartPolicyExitCode
+	var objs []runtime.Object
+	for _, pod := range []*corev1.Pod{
+		NewXGBoostPod(job, v1xgboost.XGBoostReplicaTypeMaster, 0),
+		NewXGBoostPod(job, v1xgboost.XGBoostReplicaTypeWorker, 0),
+		NewXGBoostPod(job, v1xgboost.XGBoostReplicaTypeWorker, 1),
+	} {
+		pod.Status.Phase = corev1.PodRunning
+		objs = append(objs, pod, &corev1.Service{ObjectMeta: pod.ObjectMeta})
+	}
+	// Worker-1 is killed, all the pods are restarted in attempt 1.
+	objs[4].(*corev1.Pod).Status = newFailedPod(143, "Error").Status
+	r, podControl := newTestReconciler(t, append(objs, job)...)
+
+	key := types.NamespacedName{Namespace: job.Namespace, Name: job.Name}
+	if err := r.Get(context.Background(), key, job); err != nil {
+		t.Fatalf("Failed to get the job: %v", err)
+	}
+	jobStatus := &commonv1.JobStatus{ReplicaStatuses: map[commonv1.ReplicaType]*commonv1.ReplicaStatus{
+		commonv1.ReplicaType(v1xgboost.XGBoostReplicaTypeMaster): {Active: 1},
+		commonv1.ReplicaType(v1xgboost.XGBoostReplicaTypeWorker): {Active: 1, Failed: 1},
+	}}
+	if err := r.UpdateJobStatus(job, job.Spec.XGBReplicaSpecs, jobStatus); err != nil {
+		t.Fatalf("Failed to update the job status: %v", err)
+	}
+	// The job is the one written by the restart, its status is written on top of it.
+	if job.Status.Attempt != 1 {
+		t.Errorf("Got attempt %d in the restarted job. Expected 1", job.Status.Attempt)
+	}
+	if err := r.UpdateJobStatusInApiServer(job, jobStatus); err != nil {
+		t.Fatalf("Failed to write the job status: %v", err)
+	}
+	latest := &v1xgboost.XGBoostJob{}
+	if err := r.Get(context.Background(), key, latest); err != nil {
+		t.Fatalf("Failed to get the job: %v", err)
+	}
+	if latest.Status.Attempt != 1 || latest.Status.RestartCounts[commonv1.ReplicaType(v1xgboost.XGBoostReplicaTypeWorker)] != 1 {
+		t.Errorf("Got attempt %d and restart counts %v. Expected attempt 1 after 1 restart", latest.Status.Attempt, latest.Status.RestartCounts)
+	}
+	if len(podControl.DeletePodName) != 3 {
+		t.Errorf("Got deleted pods %v. Expected all 3 pods deleted", podControl.DeletePodName)
+	}
+
+	// The pods of the new attempt are created with its number.
+	for i := 0; i < len(objs); i += 2 {
+		if err := r.Delete(context.Background(), objs[i]); err != nil {
+			t.Fatalf("Failed to delete the pod: %v", err)
+		}
+	}
+	reconcileTestJob(t, r, job)
+	if len(podControl.Templates) != 3 {
+		t.Fatalf("Got %d created pods. Expected 3", len(podControl.Templates))
+	}
+	for _, template := range podControl.Templates {
+		env := map[string]string{}
+		for _, e := range template.Spec.Containers[0].Env {
+			env[e.Name] = e.Value
+		}
+		if env["XGBOOSTJOB_ATTEMPT"] != "1" || env["DMLC_NUM_ATTEMPT"] != "1" || template.Labels[labelXGBoostJobAttempt] != "1" {
+			t.Errorf("Got pod %s of attempt %s with XGBOOSTJOB_ATTEMPT %s and DMLC_NUM_ATTEMPT %s. Expected attempt 1",
+				template.Name, template.Labels[labelXGBoostJobAttempt], env["XGBOOSTJOB_ATTEMPT"], env["DMLC_NUM_ATTEMPT"])
+		}
+	}
+}
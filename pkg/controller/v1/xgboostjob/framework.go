@@ -0,0 +1,56 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xgboostjob
+
+import (
+	"fmt"
+
+	v1xgboost "github.com/kubeflow/xgboost-operator/pkg/apis/xgboostjob/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ClusterSpecStrategy gives the pods of a job what a training framework needs to run
+// distributed, on top of the env set by SetPodEnv. To support a new framework such as
+// CatBoost, implement it, add it to newClusterSpecStrategies and allow the framework in
+// the validation package.
+type ClusterSpecStrategy interface {
+	// SetClusterSpec sets the framework env and containers of the pod of a replica.
+	SetClusterSpec(job *v1xgboost.XGBoostJob, podTemplate *corev1.PodTemplateSpec, cluster *ClusterSpec) error
+
+	// ConfigMapData returns the files the framework mounts in every replica, or nil.
+	ConfigMapData(job *v1xgboost.XGBoostJob) (map[string]string, error)
+}
+
+// newClusterSpecStrategies returns the strategy of every supported framework.
+func newClusterSpecStrategies(trackerImage string) map[v1xgboost.Framework]ClusterSpecStrategy {
+	return map[v1xgboost.Framework]ClusterSpecStrategy{
+		v1xgboost.FrameworkXGBoost:  &xgboostStrategy{trackerImage: trackerImage},
+		v1xgboost.FrameworkLightGBM: &lightGBMStrategy{},
+	}
+}
+
+// getClusterSpecStrategy returns the strategy of the framework of the job.
+func (r *ReconcileXGBoostJob) getClusterSpecStrategy(job *v1xgboost.XGBoostJob) (ClusterSpecStrategy, error) {
+	framework := job.Spec.Framework
+	if framework == "" {
+		framework = v1xgboost.DefaultFramework
+	}
+	strategy, ok := r.strategies[framework]
+	if !ok {
+		return nil, fmt.Errorf("framework %s of XGBoostJob %s is not supported", framework, job.Name)
+	}
+	return strategy, nil
+}
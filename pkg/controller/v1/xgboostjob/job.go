@@ -17,8 +17,9 @@ package xgboostjob
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
-	"reflect"
+	"strings"
 
 	commonv1 "github.com/kubeflow/common/pkg/apis/common/v1"
 	commonutil "github.com/kubeflow/common/pkg/util"
@@ -30,7 +31,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
-	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
@@ -46,6 +47,15 @@ const (
 	xgboostJobRunningReason    = "XGBoostJobRunning"
 	xgboostJobFailedReason     = "XGBoostJobFailed"
 	xgboostJobRestartingReason = "XGBoostJobRestarting"
+	// xgboostJobBackoffLimitExceededReason is added in a job when its failed pods were
+	// restarted more times than its BackoffLimit.
+	xgboostJobBackoffLimitExceededReason = "BackoffLimitExceeded"
+
+	// gangSchedulingUnavailableReason is added in a job when it asks for gang
+	// scheduling but the operator has no volcano client to create the PodGroup.
+	gangSchedulingUnavailableReason = "GangSchedulingUnavailable"
+	// xgboostJobInvalidReason is added in a job when its spec does not pass validation.
+	xgboostJobInvalidReason = "XGBoostJobInvalid"
 )
 
 // DeleteJob deletes the job
@@ -100,6 +110,100 @@ func (r *ReconcileXGBoostJob) GetJobFromAPIClient(namespace, name string) (metav
 	return job, nil
 }
 
+// syncGangScheduling makes sure the PodGroup of a gang scheduled job exists before any
+// of its pods is created, and deletes it once the job finishes. The pods are put in the
+// PodGroup by setPodGroup. Gang scheduling is decided per job, it stays disabled in the
+// Config shared with the common job controller.
+func (r *ReconcileXGBoostJob) syncGangScheduling(xgboostjob *v1xgboost.XGBoostJob) error {
+	if !isGangSchedulingEnabled(xgboostjob) {
+		return nil
+	}
+
+	if r.VolcanoClientSet == nil {
+		return r.setGangSchedulingUnavailable(xgboostjob)
+	}
+
+	if isFinished(xgboostjob) {
+		return r.DeletePodGroup(xgboostjob)
+	}
+
+	minAvailable := computeMinAvailable(xgboostjob)
+	logger.LoggerForJob(xgboostjob).Infof("Sync PodGroup with MinMember %d", minAvailable)
+	_, err := r.SyncPodGroup(xgboostjob, minAvailable)
+	return err
+}
+
+// setGangSchedulingUnavailable records once, with an event and a condition, that the
+// pods of a job asking for gang scheduling are scheduled one by one.
+func (r *ReconcileXGBoostJob) setGangSchedulingUnavailable(xgboostjob *v1xgboost.XGBoostJob) error {
+	if isFinished(xgboostjob) {
+		return nil
+	}
+	for _, condition := range xgboostjob.Status.Conditions {
+		if condition.Type == v1xgboost.JobGangSchedulingUnavailable {
+			return nil
+		}
+	}
+
+	msg := fmt.Sprintf("XGBoostJob %s asks for gang scheduling but no volcano client is available, "+
+		"pods will be scheduled one by one.", xgboostjob.Name)
+	r.recorder.Event(xgboostjob, corev1.EventTypeWarning, gangSchedulingUnavailableReason, msg)
+	now := metav1.Now()
+	modified := xgboostjob.DeepCopy()
+	modified.Status.Conditions = append(modified.Status.Conditions, commonv1.JobCondition{
+		Type:               v1xgboost.JobGangSchedulingUnavailable,
+		Status:             corev1.ConditionTrue,
+		Reason:             gangSchedulingUnavailableReason,
+		Message:            msg,
+		LastUpdateTime:     now,
+		LastTransitionTime: now,
+	})
+	if err := r.patchStatus(xgboostjob, modified); err != nil {
+		return err
+	}
+	*xgboostjob = *modified
+	return nil
+}
+
+// setPodGroup puts the pod of a gang scheduled job in the PodGroup of the job, and gives
+// it the volcano scheduler unless it asks for another one.
+func (r *ReconcileXGBoostJob) setPodGroup(xgboostjob *v1xgboost.XGBoostJob, podTemplate *corev1.PodTemplateSpec) {
+	if !isGangSchedulingEnabled(xgboostjob) || r.VolcanoClientSet == nil {
+		return
+	}
+	if podTemplate.Spec.SchedulerName == "" {
+		podTemplate.Spec.SchedulerName = volcanoSchedulerName
+	}
+	if podTemplate.Annotations == nil {
+		podTemplate.Annotations = map[string]string{}
+	}
+	podTemplate.Annotations[gangSchedulingPodGroupAnnotation] = xgboostjob.Name
+}
+
+// failInvalidJob marks a job whose spec does not pass validation as failed. No pod is
+// created for it, since the pods could not find each other or would crash on start.
+func (r *ReconcileXGBoostJob) failInvalidJob(xgboostjob *v1xgboost.XGBoostJob, err error) error {
+	msg := fmt.Sprintf("XGBoostJob %s is failed because it is invalid: %v", xgboostjob.Name, err)
+	return r.failJob(xgboostjob, xgboostJobInvalidReason, msg)
+}
+
+// failJob marks a job as failed with the given reason. Its pods, if any, are deleted
+// according to its CleanPodPolicy once the failed job is reconciled.
+func (r *ReconcileXGBoostJob) failJob(xgboostjob *v1xgboost.XGBoostJob, reason, msg string) error {
+	logger.LoggerForJob(xgboostjob).Info(msg)
+	r.recorder.Event(xgboostjob, corev1.EventTypeWarning, reason, msg)
+
+	jobStatus := xgboostjob.Status.JobStatus.DeepCopy()
+	if jobStatus.CompletionTime == nil {
+		now := metav1.Now()
+		jobStatus.CompletionTime = &now
+	}
+	if err := commonutil.UpdateJobConditions(jobStatus, commonv1.JobFailed, reason, msg); err != nil {
+		return err
+	}
+	return r.UpdateJobStatusInApiServer(xgboostjob, jobStatus)
+}
+
 // UpdateJobStatus updates the job status and job conditions
 func (r *ReconcileXGBoostJob) UpdateJobStatus(job interface{}, replicas map[commonv1.ReplicaType]*commonv1.ReplicaSpec, jobStatus *commonv1.JobStatus) error {
 	xgboostJob, ok := job.(*v1xgboost.XGBoostJob)
@@ -107,6 +211,13 @@ func (r *ReconcileXGBoostJob) UpdateJobStatus(job interface{}, replicas map[comm
 		return fmt.Errorf("%+v is not a type of xgboostJob", xgboostJob)
 	}
 
+	// The ActiveDeadlineSeconds of the job start from StartTime.
+	if jobStatus.StartTime == nil {
+		now := metav1.Now()
+		jobStatus.StartTime = &now
+	}
+
+	coordinator := commonv1.ReplicaType(getCoordinatorType(replicas))
 	for rtype, spec := range replicas {
 		status := jobStatus.ReplicaStatuses[rtype]
 
@@ -118,7 +229,7 @@ func (r *ReconcileXGBoostJob) UpdateJobStatus(job interface{}, replicas map[comm
 		logrus.Infof("XGBoostJob=%s, ReplicaType=%s expected=%d, running=%d, succeeded=%d , failed=%d",
 			xgboostJob.Name, rtype, expected, running, succeeded, failed)
 
-		if rtype == commonv1.ReplicaType(v1xgboost.XGBoostReplicaTypeMaster) {
+		if rtype == coordinator {
 			if running > 0 {
 				msg := fmt.Sprintf("XGBoostJob %s is running.", xgboostJob.Name)
 				err := commonutil.UpdateJobConditions(jobStatus, commonv1.JobRunning, xgboostJobRunningReason, msg)
@@ -127,8 +238,12 @@ func (r *ReconcileXGBoostJob) UpdateJobStatus(job interface{}, replicas map[comm
 					return err
 				}
 			}
-			// when master is succeed, the job is finished.
-			if expected == 0 {
+			// the job is finished when the replicas required by its success policy are succeeded.
+			completed, err := r.isSucceeded(xgboostJob, replicas, jobStatus)
+			if err != nil {
+				return err
+			}
+			if completed {
 				msg := fmt.Sprintf("XGBoostJob %s is successfully completed.", xgboostJob.Name)
 				logrus.Info(msg)
 				r.Recorder.Event(xgboostJob, k8sv1.EventTypeNormal, xgboostJobSucceededReason, msg)
@@ -145,8 +260,40 @@ func (r *ReconcileXGBoostJob) UpdateJobStatus(job interface{}, replicas map[comm
 			}
 		}
 		if failed > 0 {
-			if spec.RestartPolicy == commonv1.RestartPolicyExitCode {
-				msg := fmt.Sprintf("XGBoostJob %s is restarting because %d %s replica(s) failed.", xgboostJob.Name, failed, rtype)
+			failures, failedPods, err := r.getPodFailures(xgboostJob, rtype)
+			if err != nil {
+				return err
+			}
+			reason := fmt.Sprintf("%d %s replica(s) failed", failed, rtype)
+			if len(failures) > 0 {
+				reason += ": " + describeFailures(failures)
+			}
+
+			// Only failures of the current attempt count when the whole job is restarted.
+			if len(failures) == 0 && isJobRestartScope(xgboostJob) {
+				continue
+			}
+			restarting := spec.RestartPolicy == commonv1.RestartPolicyExitCode && isRetryable(failures)
+			failedReason := xgboostJobFailedReason
+			msg := fmt.Sprintf("XGBoostJob %s is failed because %s.", xgboostJob.Name, reason)
+			if restarting && isPastBackoffLimit(xgboostJob, jobStatus) {
+				restarting = false
+				failedReason = xgboostJobBackoffLimitExceededReason
+				msg = fmt.Sprintf("XGBoostJob %s is failed because it reached its backoff limit of %d restarts and %s.",
+					xgboostJob.Name, *xgboostJob.Spec.RunPolicy.BackoffLimit, reason)
+			}
+
+			if restarting {
+				msg := fmt.Sprintf("XGBoostJob %s is restarting because %s.", xgboostJob.Name, reason)
+				if isJobRestartScope(xgboostJob) {
+					if err := r.restartJob(xgboostJob, rtype, failures); err != nil {
+						return err
+					}
+					msg = fmt.Sprintf("XGBoostJob %s is restarting all its pods in attempt %d because %s.",
+						xgboostJob.Name, xgboostJob.Status.Attempt, reason)
+				} else if err := r.restartRetryablePods(xgboostJob, failures, failedPods); err != nil {
+					return err
+				}
 				r.Recorder.Event(xgboostJob, k8sv1.EventTypeWarning, xgboostJobRestartingReason, msg)
 				err := commonutil.UpdateJobConditions(jobStatus, commonv1.JobRestarting, xgboostJobRestartingReason, msg)
 				if err != nil {
@@ -154,13 +301,12 @@ func (r *ReconcileXGBoostJob) UpdateJobStatus(job interface{}, replicas map[comm
 					return err
 				}
 			} else {
-				msg := fmt.Sprintf("XGBoostJob %s is failed because %d %s replica(s) failed.", xgboostJob.Name, failed, rtype)
-				r.Recorder.Event(xgboostJob, k8sv1.EventTypeNormal, xgboostJobFailedReason, msg)
-				if xgboostJob.Status.CompletionTime == nil {
+				r.Recorder.Event(xgboostJob, k8sv1.EventTypeNormal, failedReason, msg)
+				if jobStatus.CompletionTime == nil {
 					now := metav1.Now()
-					xgboostJob.Status.CompletionTime = &now
+					jobStatus.CompletionTime = &now
 				}
-				err := commonutil.UpdateJobConditions(jobStatus, commonv1.JobFailed, xgboostJobFailedReason, msg)
+				err := commonutil.UpdateJobConditions(jobStatus, commonv1.JobFailed, failedReason, msg)
 				if err != nil {
 					logger.LoggerForJob(xgboostJob).Infof("Append job condition error: %v", err)
 					return err
@@ -182,26 +328,70 @@ func (r *ReconcileXGBoostJob) UpdateJobStatus(job interface{}, replicas map[comm
 }
 
 // UpdateJobStatusInApiServer updates the job status in to cluster.
+// Status is written through the status subresource as a merge patch of the changed
+// fields only, so it never overwrites spec changes made by users in the meantime.
 func (r *ReconcileXGBoostJob) UpdateJobStatusInApiServer(job interface{}, jobStatus *commonv1.JobStatus) error {
 	xgboostjob, ok := job.(*v1xgboost.XGBoostJob)
 	if !ok {
 		return fmt.Errorf("%+v is not a type of XGBoostJob", xgboostjob)
 	}
 
-	// Job status passed in differs with status in job, update in basis of the passed in one.
-	if !reflect.DeepEqual(&xgboostjob.Status.JobStatus, jobStatus) {
-		xgboostjob = xgboostjob.DeepCopy()
-		xgboostjob.Status.JobStatus = *jobStatus.DeepCopy()
+	// A conflict is returned rather than retried: jobStatus was computed from the stale job,
+	// and the requeued reconcile computes it again from the latest one.
+	err := r.patchJobStatus(xgboostjob, jobStatus)
+
+	if err != nil {
+		logger.LoggerForJob(xgboostjob).Error(err, "failed to update XGBoost Job conditions in the API server")
+		return err
 	}
 
-	result := r.Update(context.Background(), xgboostjob)
+	return nil
+}
 
-	if result != nil {
-		logger.LoggerForJob(xgboostjob).Error(result, "failed to update XGBoost Job conditions in the API server")
-		return result
+// patchJobStatus patches the status of the original job to jobStatus, with the saved
+// model or the summary of the predictions once the job succeeded. The patch carries the resourceVersion of the original
+// job, so it is rejected with a conflict if the job has been changed since.
+func (r *ReconcileXGBoostJob) patchJobStatus(original *v1xgboost.XGBoostJob, jobStatus *commonv1.JobStatus) error {
+	modified := original.DeepCopy()
+	modified.Status.JobStatus = *jobStatus.DeepCopy()
+	modified.Status.RestartCounts = computeRestartCounts(original, jobStatus)
+	if commonutil.IsSucceeded(*jobStatus) && isTrainJob(original) && original.Spec.Output != nil && original.Status.Model == nil {
+		model, err := r.getModelStatus(original)
+		if err != nil {
+			return err
+		}
+		modified.Status.Model = model
 	}
+	if commonutil.IsSucceeded(*jobStatus) && !isTrainJob(original) && !isPredictionComplete(original) {
+		predictions, err := r.getPredictionStatus(original)
+		if err != nil {
+			return err
+		}
+		modified.Status.Predictions = predictions
+	}
+	return r.patchStatus(original, modified)
+}
 
-	return nil
+// patchStatus writes the status changes from original to modified as a merge patch,
+// which fails with a conflict if original is not the latest version of the job.
+func (r *ReconcileXGBoostJob) patchStatus(original, modified *v1xgboost.XGBoostJob) error {
+	data, err := client.MergeFrom(original).Data(modified)
+	if err != nil {
+		return err
+	}
+	patch := map[string]interface{}{}
+	if err := json.Unmarshal(data, &patch); err != nil {
+		return err
+	}
+	if len(patch) == 0 {
+		return nil
+	}
+	patch["metadata"] = map[string]interface{}{"resourceVersion": original.ResourceVersion}
+	if data, err = json.Marshal(patch); err != nil {
+		return err
+	}
+
+	return r.Status().Patch(context.Background(), modified, client.ConstantPatch(types.MergePatchType, data))
 }
 
 // onOwnerCreateFunc modify creation condition.
@@ -211,15 +401,8 @@ func onOwnerCreateFunc(r reconcile.Reconciler) func(event.CreateEvent) bool {
 		if !ok {
 			return true
 		}
-		scheme.Scheme.Default(xgboostJob)
 		msg := fmt.Sprintf("xgboostJob %s is created.", e.Meta.GetName())
 		logrus.Info(msg)
-		//specific the run policy
-
-		if xgboostJob.Spec.RunPolicy.CleanPodPolicy == nil {
-			xgboostJob.Spec.RunPolicy.CleanPodPolicy = new(commonv1.CleanPodPolicy)
-			xgboostJob.Spec.RunPolicy.CleanPodPolicy = &defaultCleanPodPolicy
-		}
 
 		if err := commonutil.UpdateJobConditions(&xgboostJob.Status.JobStatus, commonv1.JobCreated, xgboostJobCreatedReason, msg); err != nil {
 			log.Error(err, "append job condition error")
@@ -228,3 +411,58 @@ func onOwnerCreateFunc(r reconcile.Reconciler) func(event.CreateEvent) bool {
 		return true
 	}
 }
+
+// isSucceeded evaluates the success policy of the job. Worker-0 takes the place of the
+// Master in a job without Master.
+func (r *ReconcileXGBoostJob) isSucceeded(xgboostJob *v1xgboost.XGBoostJob, replicas map[commonv1.ReplicaType]*commonv1.ReplicaSpec, jobStatus *commonv1.JobStatus) (bool, error) {
+	masterCompleted := isReplicaCompleted(commonv1.ReplicaType(getCoordinatorType(replicas)), replicas, jobStatus)
+	if !masterCompleted && isWorkerOnly(replicas) {
+		var err error
+		if masterCompleted, err = r.isWorker0Completed(xgboostJob); err != nil {
+			return false, err
+		}
+	}
+
+	workerType := commonv1.ReplicaType(v1xgboost.XGBoostReplicaTypeWorker)
+	workersCompleted := masterCompleted
+	if _, ok := replicas[workerType]; ok {
+		workersCompleted = isReplicaCompleted(workerType, replicas, jobStatus)
+	}
+
+	switch xgboostJob.Spec.SuccessPolicy {
+	case v1xgboost.SuccessPolicyAllWorkersCompleted:
+		return workersCompleted, nil
+	case v1xgboost.SuccessPolicyMasterAndAllWorkers:
+		return masterCompleted && workersCompleted, nil
+	default:
+		return masterCompleted, nil
+	}
+}
+
+// isReplicaCompleted returns true if all the replicas of rtype are succeeded.
+func isReplicaCompleted(rtype commonv1.ReplicaType, replicas map[commonv1.ReplicaType]*commonv1.ReplicaSpec, jobStatus *commonv1.JobStatus) bool {
+	spec := replicas[rtype]
+	status := jobStatus.ReplicaStatuses[rtype]
+	if spec == nil || spec.Replicas == nil || status == nil {
+		return false
+	}
+	return status.Succeeded >= *spec.Replicas
+}
+
+// isWorker0Completed returns true if the pod of worker-0 of the job is succeeded.
+func (r *ReconcileXGBoostJob) isWorker0Completed(xgboostJob *v1xgboost.XGBoostJob) (bool, error) {
+	pods, err := r.GetPodsForJob(xgboostJob)
+	if err != nil {
+		return false, err
+	}
+	pods, err = r.FilterPodsForReplicaType(pods, strings.ToLower(string(v1xgboost.XGBoostReplicaTypeWorker)))
+	if err != nil {
+		return false, err
+	}
+	for _, pod := range pods {
+		if pod.Labels[commonv1.ReplicaIndexLabel] == "0" && pod.Status.Phase == corev1.PodSucceeded {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,297 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xgboostjob
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	commonv1 "github.com/kubeflow/common/pkg/apis/common/v1"
+	"github.com/kubeflow/common/pkg/controller.v1/common"
+	"github.com/kubeflow/common/pkg/controller.v1/control"
+	"github.com/kubeflow/common/pkg/controller.v1/expectation"
+	commonutil "github.com/kubeflow/common/pkg/util"
+	v1xgboost "github.com/kubeflow/xgboost-operator/pkg/apis/xgboostjob/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	volcanofake "volcano.sh/volcano/pkg/client/clientset/versioned/fake"
+)
+
+// newTestReconciler returns a reconciler of the given objects, whose pods and services
+// are created and deleted by fake controls.
+func newTestReconciler(t *testing.T, objs ...runtime.Object) (*ReconcileXGBoostJob, *control.FakePodControl) {
+	s := runtime.NewScheme()
+	if err := scheme.AddToScheme(s); err != nil {
+		t.Fatalf("Failed to build the scheme: %v", err)
+	}
+	if err := v1xgboost.AddToScheme(s); err != nil {
+		t.Fatalf("Failed to build the scheme: %v", err)
+	}
+
+	recorder := record.NewFakeRecorder(100)
+	podControl := &control.FakePodControl{}
+	r := &ReconcileXGBoostJob{
+		Client:     fake.NewFakeClientWithScheme(s, objs...),
+		scheme:     s,
+		recorder:   recorder,
+		strategies: newClusterSpecStrategies(""),
+	}
+	r.JobController = common.JobController{
+		Controller:     r,
+		Expectations:   expectation.NewControllerExpectations(),
+		WorkQueue:      &FakeWorkQueue{},
+		Recorder:       recorder,
+		KubeClientSet:  kubefake.NewSimpleClientset(),
+		PodControl:     podControl,
+		ServiceControl: &control.FakeServiceControl{},
+	}
+	return r, podControl
+}
+
+// reconcileTestJob reconciles the job and returns it as written in the API server.
+func reconcileTestJob(t *testing.T, r *ReconcileXGBoostJob, job *v1xgboost.XGBoostJob) *v1xgboost.XGBoostJob {
+	key := types.NamespacedName{Namespace: job.Namespace, Name: job.Name}
+	if _, err := r.Reconcile(reconcile.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("Failed to reconcile the job: %v", err)
+	}
+	latest := &v1xgboost.XGBoostJob{}
+	if err := r.Get(context.Background(), key, latest); err != nil {
+		t.Fatalf("Failed to get the job: %v", err)
+	}
+	return latest
+}
+
+// newReplicaStatuses returns the job status with the given succeeded master and workers.
+func newReplicaStatuses(master, worker int32) *commonv1.JobStatus {
+	return &commonv1.JobStatus{
+		ReplicaStatuses: map[commonv1.ReplicaType]*commonv1.ReplicaStatus{
+			commonv1.ReplicaType(v1xgboost.XGBoostReplicaTypeMaster): {Succeeded: master},
+			commonv1.ReplicaType(v1xgboost.XGBoostReplicaTypeWorker): {Succeeded: worker},
+		},
+	}
+}
+
+func TestIsSucceeded(t *testing.T) {
+	withPolicy := func(job *v1xgboost.XGBoostJob, policy v1xgboost.SuccessPolicy) *v1xgboost.XGBoostJob {
+		job.Spec.SuccessPolicy = policy
+		return job
+	}
+
+	type tc struct {
+		job       *v1xgboost.XGBoostJob
+		status    *commonv1.JobStatus
+		worker0   corev1.PodPhase
+		succeeded bool
+	}
+	testCase := []tc{
+		tc{job: NewXGBoostJobWithMaster(2), status: newReplicaStatuses(1, 0), succeeded: true},
+		tc{job: NewXGBoostJobWithMaster(2), status: newReplicaStatuses(0, 2), succeeded: false},
+		tc{job: withPolicy(NewXGBoostJobWithMaster(2), v1xgboost.SuccessPolicyMasterCompleted), status: newReplicaStatuses(1, 0), succeeded: true},
+		tc{job: withPolicy(NewXGBoostJobWithMaster(2), v1xgboost.SuccessPolicyAllWorkersCompleted), status: newReplicaStatuses(1, 1), succeeded: false},
+		tc{job: withPolicy(NewXGBoostJobWithMaster(2), v1xgboost.SuccessPolicyAllWorkersCompleted), status: newReplicaStatuses(0, 2), succeeded: true},
+		tc{job: withPolicy(NewXGBoostJobWithMaster(0), v1xgboost.SuccessPolicyAllWorkersCompleted), status: newReplicaStatuses(1, 0), succeeded: true},
+		tc{job: withPolicy(NewXGBoostJobWithMaster(2), v1xgboost.SuccessPolicyMasterAndAllWorkers), status: newReplicaStatuses(1, 1), succeeded: false},
+		tc{job: withPolicy(NewXGBoostJobWithMaster(2), v1xgboost.SuccessPolicyMasterAndAllWorkers), status: newReplicaStatuses(0, 2), succeeded: false},
+		tc{job: withPolicy(NewXGBoostJobWithMaster(2), v1xgboost.SuccessPolicyMasterAndAllWorkers), status: newReplicaStatuses(1, 2), succeeded: true},
+		tc{job: NewXGoostJob(3), status: newReplicaStatuses(0, 1), worker0: corev1.PodRunning, succeeded: false},
+		tc{job: NewXGoostJob(3), status: newReplicaStatuses(0, 1), worker0: corev1.PodSucceeded, succeeded: true},
+		tc{job: withPolicy(NewXGoostJob(3), v1xgboost.SuccessPolicyAllWorkersCompleted), status: newReplicaStatuses(0, 1), worker0: corev1.PodSucceeded, succeeded: false},
+		tc{job: withPolicy(NewXGoostJob(3), v1xgboost.SuccessPolicyMasterAndAllWorkers), status: newReplicaStatuses(0, 3), worker0: corev1.PodSucceeded, succeeded: true},
+	}
+	for i, c := range testCase {
+		r := &ReconcileXGBoostJob{Client: fake.NewFakeClientWithScheme(scheme.Scheme)}
+		r.JobController.Controller = r
+		if c.worker0 != "" {
+			worker0 := NewXGBoostPod(c.job, v1xgboost.XGBoostReplicaTypeWorker, 0)
+			worker0.Status.Phase = c.worker0
+			r.Client = fake.NewFakeClientWithScheme(scheme.Scheme, worker0)
+		}
+
+		succeeded, err := r.isSucceeded(c.job, c.job.Spec.XGBReplicaSpecs, c.status)
+		if err != nil {
+			t.Fatalf("Case %d: failed to evaluate the success policy: %v", i, err)
+		}
+		if succeeded != c.succeeded {
+			t.Errorf("Case %d: Got %v. Expected %v", i, succeeded, c.succeeded)
+		}
+	}
+}
+
+func TestReconcileJobStatus(t *testing.T) {
+	job := NewXGBoostJobWithMaster(1)
+	master := NewXGBoostPod(job, v1xgboost.XGBoostReplicaTypeMaster, 0)
+	master.Status.Phase = corev1.PodRunning
+	worker := NewXGBoostPod(job, v1xgboost.XGBoostReplicaTypeWorker, 0)
+	worker.Status.Phase = corev1.PodRunning
+	r, _ := newTestReconciler(t, job, master, worker)
+
+	type tc struct {
+		workerPhase       corev1.PodPhase
+		expectedActive    int32
+		expectedSucceeded int32
+	}
+	testCase := []tc{
+		tc{workerPhase: corev1.PodRunning, expectedActive: 1, expectedSucceeded: 0},
+		// Only the replica statuses change, the job stays running.
+		tc{workerPhase: corev1.PodSucceeded, expectedActive: 0, expectedSucceeded: 1},
+	}
+	for i, c := range testCase {
+		worker.Status.Phase = c.workerPhase
+		if err := r.Update(context.Background(), worker); err != nil {
+			t.Fatalf("Case %d: failed to update the worker pod: %v", i, err)
+		}
+		latest := reconcileTestJob(t, r, job)
+		status := latest.Status.ReplicaStatuses[commonv1.ReplicaType(v1xgboost.XGBoostReplicaTypeWorker)]
+		if status == nil || status.Active != c.expectedActive || status.Succeeded != c.expectedSucceeded {
+			t.Errorf("Case %d: Got worker status %+v. Expected %d active and %d succeeded", i, status, c.expectedActive, c.expectedSucceeded)
+		}
+		if status := latest.Status.ReplicaStatuses[commonv1.ReplicaType(v1xgboost.XGBoostReplicaTypeMaster)]; status == nil || status.Active != 1 {
+			t.Errorf("Case %d: Got master status %+v. Expected 1 active", i, status)
+		}
+	}
+}
+
+func TestReconcileInvalidJob(t *testing.T) {
+	type tc struct {
+		started        bool
+		expectedFailed bool
+	}
+	testCase := []tc{
+		tc{started: false, expectedFailed: true},
+		// The job may have been admitted before the check that fails it existed.
+		tc{started: true, expectedFailed: false},
+	}
+	for i, c := range testCase {
+		job := NewXGBoostJobWithMaster(1)
+		numRound := int32(0)
+		job.Spec.XGBParams = &v1xgboost.XGBoostParams{NumRound: &numRound}
+		if c.started {
+			now := metav1.Now()
+			job.Status.StartTime = &now
+		}
+		r, _ := newTestReconciler(t, job)
+
+		latest := reconcileTestJob(t, r, job)
+		if failed := commonutil.IsFailed(latest.Status.JobStatus); failed != c.expectedFailed {
+			t.Errorf("Case %d: Got failed %v. Expected %v", i, failed, c.expectedFailed)
+		}
+	}
+}
+
+func TestReconcileGangScheduling(t *testing.T) {
+	minAvailable := int32(2)
+	gangJob := NewXGBoostJobWithMaster(1)
+	gangJob.Name = "test-gang-xgboostjob"
+	gangJob.Spec.RunPolicy.SchedulingPolicy = &commonv1.SchedulingPolicy{MinAvailable: &minAvailable}
+	job := NewXGBoostJobWithMaster(1)
+	r, podControl := newTestReconciler(t, gangJob, job)
+	volcanoClient := volcanofake.NewSimpleClientset()
+	r.VolcanoClientSet = volcanoClient
+
+	type tc struct {
+		job               *v1xgboost.XGBoostJob
+		expectedScheduler string
+		expectedPodGroup  bool
+	}
+	testCase := []tc{
+		tc{job: gangJob, expectedScheduler: volcanoSchedulerName, expectedPodGroup: true},
+		// The gang scheduling of the previous job is not carried over.
+		tc{job: job, expectedScheduler: "", expectedPodGroup: false},
+	}
+	for i, c := range testCase {
+		podControl.Templates = nil
+		reconcileTestJob(t, r, c.job)
+		if r.Config.EnableGangScheduling {
+			t.Errorf("Case %d: Got gang scheduling enabled in the shared Config", i)
+		}
+		if len(podControl.Templates) != 2 {
+			t.Fatalf("Case %d: Got %d pods created. Expected 2", i, len(podControl.Templates))
+		}
+		for _, template := range podControl.Templates {
+			if template.Spec.SchedulerName != c.expectedScheduler {
+				t.Errorf("Case %d: Got scheduler %q. Expected %q", i, template.Spec.SchedulerName, c.expectedScheduler)
+			}
+			if group, ok := template.Annotations[gangSchedulingPodGroupAnnotation]; ok != c.expectedPodGroup || ok && group != c.job.Name {
+				t.Errorf("Case %d: Got PodGroup annotation %q. Expected one: %v", i, group, c.expectedPodGroup)
+			}
+		}
+		_, err := volcanoClient.SchedulingV1beta1().PodGroups(c.job.Namespace).Get(c.job.Name, metav1.GetOptions{})
+		if exists := err == nil; exists != c.expectedPodGroup {
+			t.Errorf("Case %d: Got PodGroup %v. Expected %v", i, exists, c.expectedPodGroup)
+		}
+	}
+
+	// The PodGroup of a finished job is deleted. The pods created by the fake control are
+	// never observed, their expectations are dropped.
+	r.Expectations = expectation.NewControllerExpectations()
+	latest := &v1xgboost.XGBoostJob{}
+	key := types.NamespacedName{Namespace: gangJob.Namespace, Name: gangJob.Name}
+	if err := r.Get(context.Background(), key, latest); err != nil {
+		t.Fatalf("Failed to get the job: %v", err)
+	}
+	latest.Status.Conditions = append(latest.Status.Conditions, commonv1.JobCondition{Type: commonv1.JobSucceeded, Status: corev1.ConditionTrue})
+	now := metav1.Now()
+	latest.Status.CompletionTime = &now
+	if err := r.Status().Update(context.Background(), latest); err != nil {
+		t.Fatalf("Failed to update the job: %v", err)
+	}
+	reconcileTestJob(t, r, gangJob)
+	if _, err := volcanoClient.SchedulingV1beta1().PodGroups(gangJob.Namespace).Get(gangJob.Name, metav1.GetOptions{}); !errors.IsNotFound(err) {
+		t.Errorf("Got PodGroup of the finished job, %v. Expected it deleted", err)
+	}
+}
+
+func TestReconcileGangSchedulingUnavailable(t *testing.T) {
+	minAvailable := int32(2)
+	job := NewXGBoostJobWithMaster(1)
+	job.Spec.RunPolicy.SchedulingPolicy = &commonv1.SchedulingPolicy{MinAvailable: &minAvailable}
+	r, _ := newTestReconciler(t, job)
+	recorder := r.recorder.(*record.FakeRecorder)
+
+	// The job falls back to one by one scheduling, which is recorded once.
+	var latest *v1xgboost.XGBoostJob
+	for i := 0; i < 2; i++ {
+		r.Expectations = expectation.NewControllerExpectations()
+		latest = reconcileTestJob(t, r, job)
+	}
+	events := 0
+	for len(recorder.Events) > 0 {
+		if strings.Contains(<-recorder.Events, gangSchedulingUnavailableReason) {
+			events++
+		}
+	}
+	if events != 1 {
+		t.Errorf("Got %d %s events. Expected 1", events, gangSchedulingUnavailableReason)
+	}
+	conditions := 0
+	for _, condition := range latest.Status.Conditions {
+		if condition.Type == v1xgboost.JobGangSchedulingUnavailable && condition.Status == corev1.ConditionTrue {
+			conditions++
+		}
+	}
+	if conditions != 1 {
+		t.Errorf("Got conditions %v. Expected one %s condition", latest.Status.Conditions, v1xgboost.JobGangSchedulingUnavailable)
+	}
+}
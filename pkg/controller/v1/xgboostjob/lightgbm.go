@@ -0,0 +1,92 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xgboostjob
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	commonv1 "github.com/kubeflow/common/pkg/apis/common/v1"
+	v1xgboost "github.com/kubeflow/xgboost-operator/pkg/apis/xgboostjob/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// machineListFileName is the key of the LightGBM machine list in the job ConfigMap.
+	machineListFileName = "mlist.txt"
+	// lightGBMConfigFileName is the key of the LightGBM network settings in the job ConfigMap.
+	lightGBMConfigFileName = "lightgbm.conf"
+
+	// envMachineListFile is the path of the mounted LightGBM machine list.
+	envMachineListFile = "LIGHTGBM_MACHINE_LIST_FILE"
+	// envLightGBMConfigFile is the path of the mounted LightGBM network settings.
+	envLightGBMConfigFile = "LIGHTGBM_CONFIG_FILE"
+)
+
+// lightGBMStrategy sets up distributed LightGBM. The replicas connect to each other
+// through the machine list mounted in every replica.
+type lightGBMStrategy struct{}
+
+var _ ClusterSpecStrategy = &lightGBMStrategy{}
+
+// SetClusterSpec sets the path of the mounted LightGBM files. The images that build the
+// machine list themselves read WORKER_ADDRS and WORKER_PORT, set by SetPodEnv. The pod is
+// named in POD_NAME and POD_NAMESPACE for the tracker -eval-log command reporting the
+// training metrics, as LightGBM runs no tracker sidecar.
+func (s *lightGBMStrategy) SetClusterSpec(job *v1xgboost.XGBoostJob, podTemplate *corev1.PodTemplateSpec, cluster *ClusterSpec) error {
+	for i := range podTemplate.Spec.Containers {
+		podTemplate.Spec.Containers[i].Env = append(podTemplate.Spec.Containers[i].Env, podRefEnv()...)
+		podTemplate.Spec.Containers[i].Env = append(podTemplate.Spec.Containers[i].Env, corev1.EnvVar{
+			Name:  envMachineListFile,
+			Value: filepath.Join(configMountPath, machineListFileName),
+		})
+		podTemplate.Spec.Containers[i].Env = append(podTemplate.Spec.Containers[i].Env, corev1.EnvVar{
+			Name:  envLightGBMConfigFile,
+			Value: filepath.Join(configMountPath, lightGBMConfigFileName),
+		})
+	}
+	return nil
+}
+
+// ConfigMapData returns the LightGBM machine list, with one "host port" line per replica
+// in rank order, and the network settings shared by all replicas.
+func (s *lightGBMStrategy) ConfigMapData(job *v1xgboost.XGBoostJob) (map[string]string, error) {
+	port, err := GetPortFromXGBoostJob(job, getCoordinatorType(job.Spec.XGBReplicaSpecs))
+	if err != nil {
+		return nil, err
+	}
+
+	var machines []string
+	for _, rtype := range []v1xgboost.XGBoostJobReplicaType{v1xgboost.XGBoostReplicaTypeMaster, v1xgboost.XGBoostReplicaTypeWorker} {
+		spec, ok := job.Spec.XGBReplicaSpecs[commonv1.ReplicaType(rtype)]
+		if !ok || spec == nil || spec.Replicas == nil {
+			continue
+		}
+		for i := 0; i < int(*spec.Replicas); i++ {
+			host := computeMasterAddr(job.Name, strings.ToLower(string(rtype)), strconv.Itoa(i))
+			machines = append(machines, fmt.Sprintf("%s %d", host, port))
+		}
+	}
+
+	config := fmt.Sprintf("num_machines = %d\nlocal_listen_port = %d\nmachine_list_filename = %s\n",
+		len(machines), port, filepath.Join(configMountPath, machineListFileName))
+	return map[string]string{
+		machineListFileName:    strings.Join(machines, "\n") + "\n",
+		lightGBMConfigFileName: config,
+	}, nil
+}
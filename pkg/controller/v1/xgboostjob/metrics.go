@@ -0,0 +1,126 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xgboostjob
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strconv"
+
+	logger "github.com/kubeflow/common/pkg/util"
+	v1xgboost "github.com/kubeflow/xgboost-operator/pkg/apis/xgboostjob/v1"
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// trainingMetricsInfo is the JSON of the training metrics annotation of the coordinator
+// pod, e.g. {"iteration": 42, "metrics": {"eval-auc": 0.91}}.
+type trainingMetricsInfo struct {
+	Iteration int32                  `json:"iteration"`
+	Metrics   map[string]interface{} `json:"metrics,omitempty"`
+}
+
+var (
+	trainingIterationDesc = prometheus.NewDesc(
+		"xgboost_operator_job_training_iteration",
+		"The last training iteration reported by the coordinator pod of an XGBoostJob.",
+		[]string{"namespace", "xgboostjob"}, nil,
+	)
+	trainingMetricDesc = prometheus.NewDesc(
+		"xgboost_operator_job_training_metric",
+		"The evaluation metrics of the last training iteration of an XGBoostJob.",
+		[]string{"namespace", "xgboostjob", "metric"}, nil,
+	)
+)
+
+// syncTrainingMetrics reports in the job status the training metrics annotated on the
+// newest coordinator pod.
+func (r *ReconcileXGBoostJob) syncTrainingMetrics(xgboostJob *v1xgboost.XGBoostJob) error {
+	pods, err := r.getCoordinatorPods(xgboostJob)
+	if err != nil {
+		return err
+	}
+
+	var annotation string
+	var created metav1.Time
+	for _, pod := range pods {
+		if value := pod.Annotations[v1xgboost.TrainingMetricsAnnotation]; value != "" && !pod.CreationTimestamp.Before(&created) {
+			annotation, created = value, pod.CreationTimestamp
+		}
+	}
+	if annotation == "" {
+		return nil
+	}
+	info := trainingMetricsInfo{}
+	if err := json.Unmarshal([]byte(annotation), &info); err != nil {
+		logger.LoggerForJob(xgboostJob).Warnf("Invalid training metrics %s: %v", annotation, err)
+		return nil
+	}
+	metrics := formatMetrics(info.Metrics)
+	if last := xgboostJob.Status.TrainingMetrics; last != nil && last.Iteration == info.Iteration && reflect.DeepEqual(last.Metrics, metrics) {
+		return nil
+	}
+
+	modified := xgboostJob.DeepCopy()
+	modified.Status.TrainingMetrics = &v1xgboost.TrainingMetricsStatus{
+		Iteration:  info.Iteration,
+		Metrics:    metrics,
+		ReportTime: metav1.Now(),
+	}
+	if err := r.patchStatus(xgboostJob, modified); err != nil {
+		return err
+	}
+	*xgboostJob = *modified
+	return nil
+}
+
+// trainingMetricsCollector exposes the training metrics in the status of the XGBoostJobs
+// as Prometheus metrics, labeled by job.
+type trainingMetricsCollector struct {
+	reader client.Reader
+}
+
+// Describe implements prometheus.Collector.
+func (c *trainingMetricsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- trainingIterationDesc
+	ch <- trainingMetricDesc
+}
+
+// Collect implements prometheus.Collector. The metrics which are not numbers are skipped.
+func (c *trainingMetricsCollector) Collect(ch chan<- prometheus.Metric) {
+	jobs := &v1xgboost.XGBoostJobList{}
+	if err := c.reader.List(context.Background(), jobs); err != nil {
+		log.Error(err, "failed to list the XGBoostJobs for their training metrics")
+		return
+	}
+	for _, job := range jobs.Items {
+		training := job.Status.TrainingMetrics
+		if training == nil {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(trainingIterationDesc, prometheus.GaugeValue,
+			float64(training.Iteration), job.Namespace, job.Name)
+		for name, value := range training.Metrics {
+			v, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			ch <- prometheus.MustNewConstMetric(trainingMetricDesc, prometheus.GaugeValue, v, job.Namespace, job.Name, name)
+		}
+	}
+}
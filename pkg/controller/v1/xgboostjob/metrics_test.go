@@ -0,0 +1,92 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xgboostjob
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	v1xgboost "github.com/kubeflow/xgboost-operator/pkg/apis/xgboostjob/v1"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestSyncTrainingMetrics(t *testing.T) {
+	s := runtime.NewScheme()
+	if err := scheme.AddToScheme(s); err != nil {
+		t.Fatalf("Failed to build the scheme: %v", err)
+	}
+	if err := v1xgboost.AddToScheme(s); err != nil {
+		t.Fatalf("Failed to build the scheme: %v", err)
+	}
+
+	job := NewXGBoostJobWithMaster(1)
+	r := &ReconcileXGBoostJob{}
+	r.JobController.Controller = r
+	newPod := func(rtype v1xgboost.XGBoostJobReplicaType, metrics string, created time.Time) *corev1.Pod {
+		pod := NewXGBoostPod(job, rtype, 0)
+		pod.Annotations[v1xgboost.TrainingMetricsAnnotation] = metrics
+		pod.CreationTimestamp = metav1.NewTime(created)
+		return pod
+	}
+	// Only the metrics of the newest coordinator pod are reported.
+	now := time.Now()
+	old := newPod(v1xgboost.XGBoostReplicaTypeMaster, `{"iteration": 80, "metrics": {"eval-auc": 0.88}}`, now.Add(-time.Hour))
+	old.Name += "-old"
+	r.Client = fake.NewFakeClientWithScheme(s, job.DeepCopy(), old,
+		newPod(v1xgboost.XGBoostReplicaTypeMaster, `{"iteration": 42, "metrics": {"eval-auc": 0.91, "eval-logloss": 0.25}}`, now),
+		newPod(v1xgboost.XGBoostReplicaTypeWorker, `{"iteration": 50}`, now))
+
+	if err := r.syncTrainingMetrics(job); err != nil {
+		t.Fatalf("Failed to sync the training metrics: %v", err)
+	}
+	expected := map[string]string{"eval-auc": "0.91", "eval-logloss": "0.25"}
+	training := job.Status.TrainingMetrics
+	if training == nil || training.Iteration != 42 || !reflect.DeepEqual(training.Metrics, expected) {
+		t.Fatalf("Got training metrics %v. Expected iteration 42 with %v", training, expected)
+	}
+	reportTime := training.ReportTime
+	if err := r.syncTrainingMetrics(job); err != nil {
+		t.Fatalf("Failed to sync the training metrics: %v", err)
+	}
+	if !job.Status.TrainingMetrics.ReportTime.Equal(&reportTime) {
+		t.Errorf("Got the same iteration reported again at %v", job.Status.TrainingMetrics.ReportTime)
+	}
+
+	// The controller exposes the training metrics of the jobs, skipping those which are not numbers.
+	job.Status.TrainingMetrics.Metrics["eval-error"] = "NaN%"
+	noMetrics := NewXGBoostJobWithMaster(1)
+	noMetrics.Name = "test-xgboostjob-no-metrics"
+	collector := &trainingMetricsCollector{reader: fake.NewFakeClientWithScheme(s, job, noMetrics)}
+	expectedText := `
+# HELP xgboost_operator_job_training_iteration The last training iteration reported by the coordinator pod of an XGBoostJob.
+# TYPE xgboost_operator_job_training_iteration gauge
+xgboost_operator_job_training_iteration{namespace="default",xgboostjob="test-xgboostjob"} 42
+# HELP xgboost_operator_job_training_metric The evaluation metrics of the last training iteration of an XGBoostJob.
+# TYPE xgboost_operator_job_training_metric gauge
+xgboost_operator_job_training_metric{metric="eval-auc",namespace="default",xgboostjob="test-xgboostjob"} 0.91
+xgboost_operator_job_training_metric{metric="eval-logloss",namespace="default",xgboostjob="test-xgboostjob"} 0.25
+`
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(expectedText)); err != nil {
+		t.Errorf("Got unexpected Prometheus metrics: %v", err)
+	}
+}
@@ -0,0 +1,206 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xgboostjob
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"reflect"
+	"sort"
+
+	commonv1 "github.com/kubeflow/common/pkg/apis/common/v1"
+	commonutil "github.com/kubeflow/common/pkg/util"
+	logger "github.com/kubeflow/common/pkg/util"
+	v1xgboost "github.com/kubeflow/xgboost-operator/pkg/apis/xgboostjob/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// modelVolumeName is the name of the volume of the claim of the model of the job.
+	modelVolumeName = "xgboostjob-model"
+	// modelMountPath is where the claim of the model of the job is mounted.
+	modelMountPath = "/xgboostjob/model"
+	// pvcScheme is the scheme of the URIs of the models on a PersistentVolumeClaim.
+	pvcScheme = "pvc"
+
+	// envJobType is the type of the job, Train, Predict or Evaluate.
+	envJobType = "XGBOOST_JOB_TYPE"
+	// envInputModel is the model of the job, its path if it is on a claim, else its URI.
+	envInputModel = "XGBOOST_INPUT_MODEL"
+	// envPredictionInfoFile is the file the output of a replica of a Predict or Evaluate
+	// job is described in, as a predictionInfo.
+	envPredictionInfoFile = "XGBOOST_PREDICTION_INFO_FILE"
+)
+
+// predictionInfo describes the output of a replica of a Predict or Evaluate job. Every
+// replica writes it as JSON to the termination message of its xgboostjob container, so
+// that it is summarized in the job status.
+type predictionInfo struct {
+	URI     string                 `json:"uri"`
+	Rows    *int64                 `json:"rows,omitempty"`
+	Metrics map[string]interface{} `json:"metrics,omitempty"`
+}
+
+// isTrainJob returns true if the job trains a model.
+func isTrainJob(job *v1xgboost.XGBoostJob) bool {
+	return job.Spec.JobType == "" || job.Spec.JobType == v1xgboost.JobTypeTrain
+}
+
+// getJobType returns the type of the job.
+func getJobType(job *v1xgboost.XGBoostJob) v1xgboost.JobType {
+	if job.Spec.JobType == "" {
+		return v1xgboost.DefaultJobType
+	}
+	return job.Spec.JobType
+}
+
+// setPodModel gives the model of the job to every container of the pod, mounting its
+// claim if it is on one, and tells the replicas of Predict and Evaluate jobs where to
+// describe their output.
+func setPodModel(job *v1xgboost.XGBoostJob, podTemplate *corev1.PodTemplateSpec) error {
+	if uri := getInputModel(job); uri != "" {
+		modelFrom := job.Spec.ModelFrom
+		u, err := url.Parse(uri)
+		if err != nil {
+			return fmt.Errorf("invalid model URI %s of XGBoostJob %s: %v", uri, job.Name, err)
+		}
+		model := uri
+		var mount *corev1.VolumeMount
+		if u.Scheme == pvcScheme {
+			model = path.Join(modelMountPath, u.Path)
+			mount = &corev1.VolumeMount{Name: modelVolumeName, MountPath: modelMountPath, ReadOnly: true}
+			podTemplate.Spec.Volumes = append(podTemplate.Spec.Volumes, corev1.Volume{
+				Name: modelVolumeName,
+				VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: u.Host, ReadOnly: true},
+				},
+			})
+		}
+		for i := range podTemplate.Spec.Containers {
+			container := &podTemplate.Spec.Containers[i]
+			container.Env = append(container.Env, corev1.EnvVar{Name: envInputModel, Value: model})
+			if mount != nil {
+				container.VolumeMounts = append(container.VolumeMounts, *mount)
+			}
+			if modelFrom.SecretName != "" {
+				container.EnvFrom = append(container.EnvFrom, corev1.EnvFromSource{
+					SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: modelFrom.SecretName}},
+				})
+			}
+		}
+	}
+
+	if isTrainJob(job) {
+		return nil
+	}
+	for i := range podTemplate.Spec.Containers {
+		container := &podTemplate.Spec.Containers[i]
+		if container.Name != v1xgboost.DefaultContainerName {
+			continue
+		}
+		infoFile := container.TerminationMessagePath
+		if infoFile == "" {
+			infoFile = corev1.TerminationMessagePathDefault
+		}
+		container.Env = append(container.Env, corev1.EnvVar{Name: envPredictionInfoFile, Value: infoFile})
+	}
+	return nil
+}
+
+// getPredictionStatus summarizes the outputs described by the succeeded replicas of a
+// Predict or Evaluate job.
+func (r *ReconcileXGBoostJob) getPredictionStatus(xgboostJob *v1xgboost.XGBoostJob) (*v1xgboost.PredictionStatus, error) {
+	pods, err := r.GetPodsForJob(xgboostJob)
+	if err != nil {
+		return nil, err
+	}
+
+	predictions := &v1xgboost.PredictionStatus{}
+	for _, pod := range pods {
+		for _, status := range pod.Status.ContainerStatuses {
+			terminated := status.State.Terminated
+			if status.Name != v1xgboost.DefaultContainerName || terminated == nil || terminated.ExitCode != 0 || terminated.Message == "" {
+				continue
+			}
+			info := predictionInfo{}
+			if err := json.Unmarshal([]byte(terminated.Message), &info); err != nil {
+				logger.LoggerForJob(xgboostJob).Warnf("Ignore the prediction info of pod %s: %v", pod.Name, err)
+				continue
+			}
+			cluster, err := newClusterSpec(xgboostJob, pod.Labels[commonv1.ReplicaTypeLabel], pod.Labels[commonv1.ReplicaIndexLabel])
+			if err != nil {
+				logger.LoggerForJob(xgboostJob).Warnf("Ignore the prediction info of pod %s: %v", pod.Name, err)
+				continue
+			}
+
+			output := v1xgboost.PredictionOutput{Rank: int32(cluster.Rank), URI: info.URI, Rows: info.Rows, Metrics: formatMetrics(info.Metrics)}
+			if info.Rows != nil {
+				predictions.Rows += *info.Rows
+			}
+			predictions.Outputs = append(predictions.Outputs, output)
+		}
+	}
+	sort.Slice(predictions.Outputs, func(i, j int) bool {
+		return predictions.Outputs[i].Rank < predictions.Outputs[j].Rank
+	})
+	return predictions, nil
+}
+
+// isPredictionComplete returns true once every replica of a Predict or Evaluate job has
+// described its output in the prediction summary.
+func isPredictionComplete(xgboostJob *v1xgboost.XGBoostJob) bool {
+	predictions := xgboostJob.Status.Predictions
+	return predictions != nil && int32(len(predictions.Outputs)) >= computeTotalReplicas(xgboostJob)
+}
+
+// syncPredictions adds to the prediction summary of a succeeded Predict or Evaluate job
+// the outputs of the replicas that completed after it succeeded, e.g. the workers of a
+// job with the MasterCompleted success policy.
+func (r *ReconcileXGBoostJob) syncPredictions(xgboostJob *v1xgboost.XGBoostJob) error {
+	if isTrainJob(xgboostJob) || !commonutil.IsSucceeded(xgboostJob.Status.JobStatus) || isPredictionComplete(xgboostJob) {
+		return nil
+	}
+	predictions, err := r.getPredictionStatus(xgboostJob)
+	if err != nil {
+		return err
+	}
+	if reflect.DeepEqual(predictions, xgboostJob.Status.Predictions) {
+		return nil
+	}
+
+	modified := xgboostJob.DeepCopy()
+	modified.Status.Predictions = predictions
+	if err := r.patchStatus(xgboostJob, modified); err != nil {
+		return err
+	}
+	*xgboostJob = *modified
+	return nil
+}
+
+// formatMetrics returns the metrics reported by a replica as strings, or nil if there is
+// none.
+func formatMetrics(metrics map[string]interface{}) map[string]string {
+	if len(metrics) == 0 {
+		return nil
+	}
+	formatted := make(map[string]string, len(metrics))
+	for name, value := range metrics {
+		formatted[name] = fmt.Sprint(value)
+	}
+	return formatted
+}
@@ -0,0 +1,191 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xgboostjob
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	commonv1 "github.com/kubeflow/common/pkg/apis/common/v1"
+	v1xgboost "github.com/kubeflow/xgboost-operator/pkg/apis/xgboostjob/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestPodModel(t *testing.T) {
+	onClaim := NewXGBoostJobWithMaster(1)
+	onClaim.Spec.JobType = v1xgboost.JobTypePredict
+	onClaim.Spec.ModelFrom = &v1xgboost.ModelSource{URI: "pvc://models/iris/model.json"}
+
+	inObjectStore := NewXGBoostJobWithMaster(1)
+	inObjectStore.Spec.JobType = v1xgboost.JobTypeEvaluate
+	inObjectStore.Spec.ModelFrom = &v1xgboost.ModelSource{URI: "oss://models/iris/model.json", SecretName: "oss-credentials"}
+
+	training := NewXGBoostJobWithMaster(1)
+
+	type tc struct {
+		job            *v1xgboost.XGBoostJob
+		expectedEnv    map[string]string
+		expectedMounts int
+		expectedSecret bool
+	}
+	testCase := []tc{
+		tc{
+			job:            onClaim,
+			expectedEnv:    map[string]string{envJobType: "Predict", envInputModel: "/xgboostjob/model/iris/model.json", envPredictionInfoFile: corev1.TerminationMessagePathDefault},
+			expectedMounts: 1,
+		},
+		tc{
+			job:            inObjectStore,
+			expectedEnv:    map[string]string{envJobType: "Evaluate", envInputModel: "oss://models/iris/model.json", envPredictionInfoFile: corev1.TerminationMessagePathDefault},
+			expectedSecret: true,
+		},
+		tc{
+			job:         training,
+			expectedEnv: map[string]string{envJobType: "Train", envInputModel: "", envPredictionInfoFile: ""},
+		},
+	}
+	for i, c := range testCase {
+		podTemplate := c.job.Spec.XGBReplicaSpecs[commonv1.ReplicaType(v1xgboost.XGBoostReplicaTypeWorker)].Template.DeepCopy()
+		if err := SetPodEnv(c.job, podTemplate, "worker", "0"); err != nil {
+			t.Fatalf("Case %d: failed to set pod env: %v", i, err)
+		}
+		if err := setPodModel(c.job, podTemplate); err != nil {
+			t.Fatalf("Case %d: failed to set pod model: %v", i, err)
+		}
+
+		container := podTemplate.Spec.Containers[0]
+		actual := map[string]string{}
+		for _, env := range container.Env {
+			actual[env.Name] = env.Value
+		}
+		for name, val := range c.expectedEnv {
+			if actual[name] != val {
+				t.Errorf("Case %d: for name %s Got %s. Expected %s", i, name, actual[name], val)
+			}
+		}
+		if len(container.VolumeMounts) != c.expectedMounts || len(podTemplate.Spec.Volumes) != c.expectedMounts {
+			t.Errorf("Case %d: Got volume mounts %v and volumes %v", i, container.VolumeMounts, podTemplate.Spec.Volumes)
+		}
+		if c.expectedMounts > 0 && podTemplate.Spec.Volumes[0].PersistentVolumeClaim.ClaimName != "models" {
+			t.Errorf("Case %d: Got volume %v. Expected the claim models", i, podTemplate.Spec.Volumes[0])
+		}
+		if hasSecret := len(container.EnvFrom) == 1; hasSecret != c.expectedSecret {
+			t.Errorf("Case %d: Got env from %v", i, container.EnvFrom)
+		}
+	}
+}
+
+func TestPredictionStatus(t *testing.T) {
+	job := NewXGBoostJobWithMaster(2)
+	job.Spec.JobType = v1xgboost.JobTypeEvaluate
+	job.Spec.ModelFrom = &v1xgboost.ModelSource{URI: "oss://models/iris/model.json"}
+	r := &ReconcileXGBoostJob{}
+	r.JobController.Controller = r
+	newPod := func(rtype v1xgboost.XGBoostJobReplicaType, index int, message string) runtime.Object {
+		pod := NewXGBoostPod(job, rtype, index)
+		pod.Status = corev1.PodStatus{
+			Phase: corev1.PodSucceeded,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:  v1xgboost.DefaultContainerName,
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Message: message}},
+			}},
+		}
+		return pod
+	}
+	r.Client = fake.NewFakeClientWithScheme(scheme.Scheme,
+		newPod(v1xgboost.XGBoostReplicaTypeWorker, 1, `{"uri": "oss://predictions/iris/2.csv", "rows": 50, "metrics": {"auc": 0.97}}`),
+		newPod(v1xgboost.XGBoostReplicaTypeMaster, 0, `{"uri": "oss://predictions/iris/0.csv", "rows": 60}`),
+		newPod(v1xgboost.XGBoostReplicaTypeWorker, 0, "not json"))
+
+	predictions, err := r.getPredictionStatus(job)
+	if err != nil {
+		t.Fatalf("Failed to get the predictions: %v", err)
+	}
+	if predictions.Rows != 110 || len(predictions.Outputs) != 2 {
+		t.Fatalf("Got predictions %+v. Expected 110 rows in 2 outputs", predictions)
+	}
+	first, second := predictions.Outputs[0], predictions.Outputs[1]
+	if first.Rank != 0 || first.URI != "oss://predictions/iris/0.csv" || len(first.Metrics) != 0 {
+		t.Errorf("Got output %+v of rank 0", first)
+	}
+	if second.Rank != 2 || second.URI != "oss://predictions/iris/2.csv" || second.Metrics["auc"] != "0.97" {
+		t.Errorf("Got output %+v of rank 2", second)
+	}
+}
+
+func TestSyncPredictions(t *testing.T) {
+	job := NewXGBoostJobWithMaster(2)
+	job.Spec.JobType = v1xgboost.JobTypeEvaluate
+	job.Spec.SuccessPolicy = v1xgboost.SuccessPolicyMasterCompleted
+	job.Status.Conditions = []commonv1.JobCondition{{Type: commonv1.JobSucceeded, Status: corev1.ConditionTrue}}
+	job.Status.Predictions = &v1xgboost.PredictionStatus{Outputs: []v1xgboost.PredictionOutput{{Rank: 0, URI: "oss://predictions/iris/0.csv"}}}
+	// The pods in rank order.
+	pods := []*corev1.Pod{
+		NewXGBoostPod(job, v1xgboost.XGBoostReplicaTypeMaster, 0),
+		NewXGBoostPod(job, v1xgboost.XGBoostReplicaTypeWorker, 0),
+		NewXGBoostPod(job, v1xgboost.XGBoostReplicaTypeWorker, 1),
+	}
+	for _, pod := range pods {
+		pod.Status.Phase = corev1.PodRunning
+	}
+	r, _ := newTestReconciler(t, job, pods[0], pods[1], pods[2])
+	key := types.NamespacedName{Namespace: job.Namespace, Name: job.Name}
+
+	type tc struct {
+		completed        int
+		expectedOutputs  int
+		expectedComplete bool
+	}
+	testCase := []tc{
+		tc{completed: 2, expectedOutputs: 2, expectedComplete: false},
+		tc{completed: 3, expectedOutputs: 3, expectedComplete: true},
+	}
+	for i, c := range testCase {
+		for rank, pod := range pods[:c.completed] {
+			pod.Status = corev1.PodStatus{
+				Phase: corev1.PodSucceeded,
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name:  v1xgboost.DefaultContainerName,
+					State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Message: fmt.Sprintf(`{"uri": "oss://predictions/iris/%d.csv"}`, rank)}},
+				}},
+			}
+			if err := r.Update(context.Background(), pod); err != nil {
+				t.Fatalf("Case %d: failed to update pod %s: %v", i, pod.Name, err)
+			}
+		}
+		latest := &v1xgboost.XGBoostJob{}
+		if err := r.Get(context.Background(), key, latest); err != nil {
+			t.Fatalf("Case %d: failed to get the job: %v", i, err)
+		}
+		if err := r.syncPredictions(latest); err != nil {
+			t.Fatalf("Case %d: failed to sync the predictions: %v", i, err)
+		}
+		if err := r.Get(context.Background(), key, latest); err != nil {
+			t.Fatalf("Case %d: failed to get the job: %v", i, err)
+		}
+		if outputs := len(latest.Status.Predictions.Outputs); outputs != c.expectedOutputs {
+			t.Errorf("Case %d: Got %d outputs. Expected %d", i, outputs, c.expectedOutputs)
+		}
+		if complete := isPredictionComplete(latest); complete != c.expectedComplete {
+			t.Errorf("Case %d: Got complete %v. Expected %v", i, complete, c.expectedComplete)
+		}
+	}
+}
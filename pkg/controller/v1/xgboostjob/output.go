@@ -0,0 +1,155 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xgboostjob
+
+import (
+	"encoding/json"
+	"path"
+	"path/filepath"
+	"strings"
+
+	logger "github.com/kubeflow/common/pkg/util"
+	v1xgboost "github.com/kubeflow/xgboost-operator/pkg/apis/xgboostjob/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// outputVolumeName is the name of the volume of the output claim.
+	outputVolumeName = "xgboostjob-output"
+
+	// envModelDir is the directory of the output claim the model is saved to.
+	envModelDir = "XGBOOST_MODEL_DIR"
+	// envModelURI is the object store location the model is saved to.
+	envModelURI = "XGBOOST_MODEL_URI"
+	// envModelInfoFile is the file the saved model is described in, as a modelInfo.
+	envModelInfoFile = "XGBOOST_MODEL_INFO_FILE"
+)
+
+// modelInfo describes the saved model. The coordinator writes it as JSON to the
+// termination message of its xgboostjob container, so that it is reported in the job
+// status.
+type modelInfo struct {
+	// URI of the model, or its path in the container if it is saved on the output claim.
+	URI     string                 `json:"uri"`
+	Size    *int64                 `json:"size,omitempty"`
+	Format  string                 `json:"format,omitempty"`
+	Metrics map[string]interface{} `json:"metrics,omitempty"`
+}
+
+// modelFormats are the formats of the models by file extension.
+var modelFormats = map[string]string{
+	".json":  "json",
+	".ubj":   "ubj",
+	".bin":   "binary",
+	".model": "binary",
+	".txt":   "text",
+}
+
+// setPodOutput tells the xgboostjob container of the coordinator pod where to save the
+// model, mounting the output claim and the environment of the output secret.
+func setPodOutput(job *v1xgboost.XGBoostJob, podTemplate *corev1.PodTemplateSpec, cluster *ClusterSpec) {
+	output := job.Spec.Output
+	if output == nil || !cluster.IsCoordinator() {
+		return
+	}
+
+	mountPath := output.Path
+	if mountPath == "" {
+		mountPath = v1xgboost.DefaultOutputPath
+	}
+	if output.ClaimName != "" {
+		podTemplate.Spec.Volumes = append(podTemplate.Spec.Volumes, corev1.Volume{
+			Name: outputVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: output.ClaimName},
+			},
+		})
+	}
+	for i := range podTemplate.Spec.Containers {
+		container := &podTemplate.Spec.Containers[i]
+		if container.Name != v1xgboost.DefaultContainerName {
+			continue
+		}
+		if output.ClaimName != "" {
+			container.Env = append(container.Env, corev1.EnvVar{Name: envModelDir, Value: mountPath})
+			container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+				Name:      outputVolumeName,
+				MountPath: mountPath,
+				SubPath:   output.SubPath,
+			})
+		} else {
+			container.Env = append(container.Env, corev1.EnvVar{Name: envModelURI, Value: output.URI})
+		}
+		infoFile := container.TerminationMessagePath
+		if infoFile == "" {
+			infoFile = corev1.TerminationMessagePathDefault
+		}
+		container.Env = append(container.Env, corev1.EnvVar{Name: envModelInfoFile, Value: infoFile})
+		if output.SecretName != "" {
+			container.EnvFrom = append(container.EnvFrom, corev1.EnvFromSource{
+				SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: output.SecretName}},
+			})
+		}
+	}
+}
+
+// getModelStatus returns the model saved by the succeeded coordinator of the job. Without
+// model info, the model is the output location of the job.
+func (r *ReconcileXGBoostJob) getModelStatus(xgboostJob *v1xgboost.XGBoostJob) (*v1xgboost.ModelStatus, error) {
+	pods, err := r.getCoordinatorPods(xgboostJob)
+	if err != nil {
+		return nil, err
+	}
+
+	info := modelInfo{}
+	for _, pod := range pods {
+		for _, status := range pod.Status.ContainerStatuses {
+			terminated := status.State.Terminated
+			if status.Name != v1xgboost.DefaultContainerName || terminated == nil || terminated.ExitCode != 0 || terminated.Message == "" {
+				continue
+			}
+			if err := json.Unmarshal([]byte(terminated.Message), &info); err != nil {
+				logger.LoggerForJob(xgboostJob).Warnf("Ignore the model info of pod %s: %v", pod.Name, err)
+			}
+		}
+	}
+	return newModelStatus(xgboostJob.Spec.Output, info), nil
+}
+
+// newModelStatus returns the status of the model described by info, saved to output.
+// Models on the output claim get a pvc://<claim name>/<path> URI.
+func newModelStatus(output *v1xgboost.OutputSpec, info modelInfo) *v1xgboost.ModelStatus {
+	model := &v1xgboost.ModelStatus{URI: info.URI, SizeBytes: info.Size, Format: info.Format, Metrics: formatMetrics(info.Metrics)}
+	if output.ClaimName != "" {
+		mountPath := output.Path
+		if mountPath == "" {
+			mountPath = v1xgboost.DefaultOutputPath
+		}
+		model.URI = "pvc://" + output.ClaimName
+		if subPath := path.Clean(output.SubPath); output.SubPath != "" && subPath != "." {
+			model.URI += "/" + subPath
+		}
+		if rel, err := filepath.Rel(mountPath, info.URI); err == nil && rel != "." && !strings.HasPrefix(rel, "..") {
+			model.URI += "/" + rel
+		}
+	} else if model.URI == "" {
+		model.URI = output.URI
+	}
+	if model.Format == "" {
+		model.Format = modelFormats[path.Ext(model.URI)]
+	}
+	return model
+}
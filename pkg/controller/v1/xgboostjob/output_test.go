@@ -0,0 +1,137 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xgboostjob
+
+import (
+	"reflect"
+	"testing"
+
+	commonv1 "github.com/kubeflow/common/pkg/apis/common/v1"
+	v1xgboost "github.com/kubeflow/xgboost-operator/pkg/apis/xgboostjob/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestPodOutput(t *testing.T) {
+	withClaim := NewXGBoostJobWithMaster(1)
+	withClaim.Spec.Output = &v1xgboost.OutputSpec{ClaimName: "models"}
+
+	withURI := NewXGBoostJobWithMaster(1)
+	withURI.Spec.Output = &v1xgboost.OutputSpec{URI: "oss://models/iris.json", SecretName: "oss-credentials"}
+
+	type tc struct {
+		job            *v1xgboost.XGBoostJob
+		rt             v1xgboost.XGBoostJobReplicaType
+		expectedEnv    map[string]string
+		expectedVolume bool
+		expectedSecret bool
+	}
+	testCase := []tc{
+		tc{
+			job:            withClaim,
+			rt:             v1xgboost.XGBoostReplicaTypeMaster,
+			expectedEnv:    map[string]string{envModelDir: v1xgboost.DefaultOutputPath, envModelInfoFile: corev1.TerminationMessagePathDefault},
+			expectedVolume: true,
+		},
+		tc{
+			job:            withURI,
+			rt:             v1xgboost.XGBoostReplicaTypeMaster,
+			expectedEnv:    map[string]string{envModelURI: "oss://models/iris.json", envModelInfoFile: corev1.TerminationMessagePathDefault},
+			expectedSecret: true,
+		},
+		tc{
+			job:         withClaim,
+			rt:          v1xgboost.XGBoostReplicaTypeWorker,
+			expectedEnv: map[string]string{},
+		},
+	}
+	for i, c := range testCase {
+		podTemplate := c.job.Spec.XGBReplicaSpecs[commonv1.ReplicaType(c.rt)].Template.DeepCopy()
+		cluster, err := newClusterSpec(c.job, string(c.rt), "0")
+		if err != nil {
+			t.Fatalf("Case %d: failed to get cluster spec: %v", i, err)
+		}
+		setPodOutput(c.job, podTemplate, cluster)
+
+		container := podTemplate.Spec.Containers[0]
+		if len(container.Env) != len(c.expectedEnv) {
+			t.Errorf("Case %d: Got env %v. Expected %v", i, container.Env, c.expectedEnv)
+		}
+		for _, env := range container.Env {
+			if c.expectedEnv[env.Name] != env.Value {
+				t.Errorf("Case %d: for name %s Got %s. Expected %s", i, env.Name, env.Value, c.expectedEnv[env.Name])
+			}
+		}
+		if hasVolume := len(podTemplate.Spec.Volumes) == 1 && len(container.VolumeMounts) == 1; hasVolume != c.expectedVolume {
+			t.Errorf("Case %d: Got volumes %v. Expected output volume %v", i, podTemplate.Spec.Volumes, c.expectedVolume)
+		}
+		if hasSecret := len(container.EnvFrom) == 1; hasSecret != c.expectedSecret {
+			t.Errorf("Case %d: Got env from %v. Expected output secret %v", i, container.EnvFrom, c.expectedSecret)
+		}
+	}
+}
+
+func TestModelStatus(t *testing.T) {
+	size := int64(1024)
+	claim := &v1xgboost.OutputSpec{ClaimName: "models", Path: "/models"}
+	subPath := &v1xgboost.OutputSpec{ClaimName: "models", Path: "/models", SubPath: "sweep/trial-0"}
+	uri := &v1xgboost.OutputSpec{URI: "oss://models/iris"}
+
+	type tc struct {
+		output   *v1xgboost.OutputSpec
+		message  string
+		expected v1xgboost.ModelStatus
+	}
+	testCase := []tc{
+		tc{output: claim, message: "", expected: v1xgboost.ModelStatus{URI: "pvc://models"}},
+		tc{output: claim, message: `{"uri": "/models/iris/model.json", "size": 1024}`, expected: v1xgboost.ModelStatus{URI: "pvc://models/iris/model.json", SizeBytes: &size, Format: "json"}},
+		tc{output: claim, message: `{"uri": "/tmp/model.json"}`, expected: v1xgboost.ModelStatus{URI: "pvc://models"}},
+		tc{output: subPath, message: `{"uri": "/models/model.json"}`, expected: v1xgboost.ModelStatus{URI: "pvc://models/sweep/trial-0/model.json", Format: "json"}},
+		tc{output: uri, message: "not json", expected: v1xgboost.ModelStatus{URI: "oss://models/iris"}},
+		tc{output: uri, message: `{"uri": "oss://models/iris/model.bin", "format": "ubj"}`, expected: v1xgboost.ModelStatus{URI: "oss://models/iris/model.bin", Format: "ubj"}},
+		tc{output: uri, message: `{"uri": "oss://models/iris/model.json", "metrics": {"validation-auc": 0.97}}`,
+			expected: v1xgboost.ModelStatus{URI: "oss://models/iris/model.json", Format: "json", Metrics: map[string]string{"validation-auc": "0.97"}}},
+	}
+	for i, c := range testCase {
+		job := NewXGBoostJobWithMaster(1)
+		job.Spec.Output = c.output
+		r := &ReconcileXGBoostJob{}
+		r.JobController.Controller = r
+		labels := r.GenLabels(job.Name)
+		labels[commonv1.JobRoleLabel] = "master"
+		r.Client = fake.NewFakeClientWithScheme(scheme.Scheme, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-xgboostjob-master-0", Namespace: job.Namespace, Labels: labels},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodSucceeded,
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name:  v1xgboost.DefaultContainerName,
+					State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Message: c.message}},
+				}},
+			},
+		})
+
+		model, err := r.getModelStatus(job)
+		if err != nil {
+			t.Fatalf("Case %d: failed to get the model: %v", i, err)
+		}
+		if model.URI != c.expected.URI || model.Format != c.expected.Format || !reflect.DeepEqual(model.Metrics, c.expected.Metrics) ||
+			(model.SizeBytes == nil) != (c.expected.SizeBytes == nil) || (model.SizeBytes != nil && *model.SizeBytes != *c.expected.SizeBytes) {
+			t.Errorf("Case %d: Got %+v. Expected %+v", i, model, c.expected)
+		}
+	}
+}
@@ -47,6 +47,18 @@ func (r *ReconcileXGBoostJob) GetPodsForJob(obj interface{}) ([]*corev1.Pod, err
 	return convertPodList(podlist.Items), nil
 }
 
+// getCoordinatorPods returns the pods of the coordinator of the job, Master-0, or Worker-0
+// if the job has no Master.
+func (r *ReconcileXGBoostJob) getCoordinatorPods(xgboostJob *v1xgboost.XGBoostJob) ([]corev1.Pod, error) {
+	labels := r.GenLabels(xgboostJob.Name)
+	labels[commonv1.JobRoleLabel] = "master"
+	podList := &corev1.PodList{}
+	if err := r.List(context.Background(), podList, client.InNamespace(xgboostJob.Namespace), client.MatchingLabels(labels)); err != nil {
+		return nil, err
+	}
+	return podList.Items, nil
+}
+
 // convertPodList convert pod list to pod point list
 func convertPodList(list []corev1.Pod) []*corev1.Pod {
 	if list == nil {
@@ -59,77 +71,130 @@ func convertPodList(list []corev1.Pod) []*corev1.Pod {
 	return ret
 }
 
-// SetPodEnv sets the pod env set for:
-// - XGBoost Rabit Tracker and worker
-// - LightGBM master and workers
-func SetPodEnv(job interface{}, podTemplate *corev1.PodTemplateSpec, rtype, index string) error {
-	xgboostjob, ok := job.(*v1xgboost.XGBoostJob)
-	if !ok {
-		return fmt.Errorf("%+v is not a type of XGBoostJob", xgboostjob)
-	}
+// ClusterSpec describes the place of a pod in the job.
+type ClusterSpec struct {
+	// ReplicaType and Index identify the replica run by the pod.
+	ReplicaType v1xgboost.XGBoostJobReplicaType
+	Index       int
+	// Rank is the rank of the replica among all the replicas, the coordinator being 0.
+	Rank int
+	// WorldSize is the total number of replicas.
+	WorldSize int32
+	// MasterAddr and MasterPort are the address of the coordinator, Master-0, or
+	// Worker-0 if the job has no Master.
+	MasterAddr string
+	MasterPort int32
+	// Attempt is the number of times all the pods of the job were restarted together.
+	Attempt int32
+}
+
+// IsCoordinator returns true if the pod coordinates the job.
+func (c *ClusterSpec) IsCoordinator() bool {
+	return c.Rank == 0
+}
 
+// newClusterSpec returns the cluster spec of the replica rtype-index.
+func newClusterSpec(xgboostjob *v1xgboost.XGBoostJob, rtype, index string) (*ClusterSpec, error) {
 	rank, err := strconv.Atoi(index)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	cluster := &ClusterSpec{
+		ReplicaType: v1xgboost.XGBoostReplicaTypeMaster,
+		Index:       rank,
 	}
 
 	// Add master offset for worker pods
 	if strings.ToLower(rtype) == strings.ToLower(string(v1xgboost.XGBoostReplicaTypeWorker)) {
+		cluster.ReplicaType = v1xgboost.XGBoostReplicaTypeWorker
 		masterSpec := xgboostjob.Spec.XGBReplicaSpecs[commonv1.ReplicaType(v1xgboost.XGBoostReplicaTypeMaster)]
-		masterReplicas := int(*masterSpec.Replicas)
-		rank += masterReplicas
+		if masterSpec != nil && masterSpec.Replicas != nil {
+			rank += int(*masterSpec.Replicas)
+		}
 	}
+	cluster.Rank = rank
 
-	masterAddr := computeMasterAddr(xgboostjob.Name, strings.ToLower(string(v1xgboost.XGBoostReplicaTypeMaster)), strconv.Itoa(0))
+	coordinator := getCoordinatorType(xgboostjob.Spec.XGBReplicaSpecs)
+	cluster.MasterAddr = computeMasterAddr(xgboostjob.Name, strings.ToLower(string(coordinator)), strconv.Itoa(0))
+	cluster.MasterPort, err = GetPortFromXGBoostJob(xgboostjob, coordinator)
+	if err != nil {
+		return nil, err
+	}
+	cluster.WorldSize = computeTotalReplicas(xgboostjob)
+	cluster.Attempt = xgboostjob.Status.Attempt
+	return cluster, nil
+}
+
+// SetPodEnv sets the pod env shared by all frameworks, the env specific to a framework
+// is set by its ClusterSpecStrategy. WORKER_PORT and WORKER_ADDRS are set for every
+// framework, LightGBM images written before spec.framework existed read them.
+func SetPodEnv(job interface{}, podTemplate *corev1.PodTemplateSpec, rtype, index string) error {
+	xgboostjob, ok := job.(*v1xgboost.XGBoostJob)
+	if !ok {
+		return fmt.Errorf("%+v is not a type of XGBoostJob", xgboostjob)
+	}
 
-	masterPort, err := GetPortFromXGBoostJob(xgboostjob, v1xgboost.XGBoostReplicaTypeMaster)
+	cluster, err := newClusterSpec(xgboostjob, rtype, index)
 	if err != nil {
 		return err
 	}
 
-	totalReplicas := computeTotalReplicas(xgboostjob)
-
 	var workerPort int32
 	var workerAddrs []string
 
-	if totalReplicas > 1 {
+	if cluster.WorldSize > 1 {
 		workerPortTemp, err := GetPortFromXGBoostJob(xgboostjob, v1xgboost.XGBoostReplicaTypeWorker)
 		if err != nil {
 			return err
 		}
 		workerPort = workerPortTemp
-		workerAddrs = make([]string, totalReplicas-1)
+		// The workers are all the replicas but the coordinator, which is Worker-0
+		// when the job has no Master.
+		offset := 0
+		if isWorkerOnly(xgboostjob.Spec.XGBReplicaSpecs) {
+			offset = 1
+		}
+		workerAddrs = make([]string, cluster.WorldSize-1)
 		for i := range workerAddrs {
-			workerAddrs[i] = computeMasterAddr(xgboostjob.Name, strings.ToLower(string(v1xgboost.XGBoostReplicaTypeWorker)), strconv.Itoa(i))
+			workerAddrs[i] = computeMasterAddr(xgboostjob.Name, strings.ToLower(string(v1xgboost.XGBoostReplicaTypeWorker)), strconv.Itoa(i+offset))
 		}
 	}
 
+	dataEnvs := genDataEnv(xgboostjob, cluster)
 	for i := range podTemplate.Spec.Containers {
 		if len(podTemplate.Spec.Containers[i].Env) == 0 {
 			podTemplate.Spec.Containers[i].Env = make([]corev1.EnvVar, 0)
 		}
 		podTemplate.Spec.Containers[i].Env = append(podTemplate.Spec.Containers[i].Env, corev1.EnvVar{
 			Name:  "MASTER_PORT",
-			Value: strconv.Itoa(int(masterPort)),
+			Value: strconv.Itoa(int(cluster.MasterPort)),
 		})
 		podTemplate.Spec.Containers[i].Env = append(podTemplate.Spec.Containers[i].Env, corev1.EnvVar{
 			Name:  "MASTER_ADDR",
-			Value: masterAddr,
+			Value: cluster.MasterAddr,
 		})
 		podTemplate.Spec.Containers[i].Env = append(podTemplate.Spec.Containers[i].Env, corev1.EnvVar{
 			Name:  "WORLD_SIZE",
-			Value: strconv.Itoa(int(totalReplicas)),
+			Value: strconv.Itoa(int(cluster.WorldSize)),
 		})
 		podTemplate.Spec.Containers[i].Env = append(podTemplate.Spec.Containers[i].Env, corev1.EnvVar{
 			Name:  "RANK",
-			Value: strconv.Itoa(rank),
+			Value: strconv.Itoa(cluster.Rank),
+		})
+		podTemplate.Spec.Containers[i].Env = append(podTemplate.Spec.Containers[i].Env, corev1.EnvVar{
+			Name:  "XGBOOSTJOB_ATTEMPT",
+			Value: strconv.Itoa(int(cluster.Attempt)),
+		})
+		podTemplate.Spec.Containers[i].Env = append(podTemplate.Spec.Containers[i].Env, corev1.EnvVar{
+			Name:  envJobType,
+			Value: string(getJobType(xgboostjob)),
 		})
 		podTemplate.Spec.Containers[i].Env = append(podTemplate.Spec.Containers[i].Env, corev1.EnvVar{
 			Name:  "PYTHONUNBUFFERED",
 			Value: "0",
 		})
 		// This variables are used if it is a LightGBM job
-		if totalReplicas > 1 {
+		if cluster.WorldSize > 1 {
 			podTemplate.Spec.Containers[i].Env = append(podTemplate.Spec.Containers[i].Env, corev1.EnvVar{
 				Name:  "WORKER_PORT",
 				Value: strconv.Itoa(int(workerPort)),
@@ -139,6 +204,7 @@ func SetPodEnv(job interface{}, podTemplate *corev1.PodTemplateSpec, rtype, inde
 				Value: strings.Join(workerAddrs, ","),
 			})
 		}
+		podTemplate.Spec.Containers[i].Env = append(podTemplate.Spec.Containers[i].Env, dataEnvs...)
 	}
 
 	return nil
@@ -16,9 +16,12 @@ limitations under the License.
 package xgboostjob
 
 import (
+	"strconv"
+	"strings"
 	"testing"
 
 	commonv1 "github.com/kubeflow/common/pkg/apis/common/v1"
+	"github.com/kubeflow/common/pkg/controller.v1/common"
 	v1xgboost "github.com/kubeflow/xgboost-operator/pkg/apis/xgboostjob/v1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -62,6 +65,28 @@ func NewXGoostJob(worker int) *v1xgboost.XGBoostJob {
 	return job
 }
 
+// NewXGBoostPod returns the pod of the replica rtype-index of the job, with the name and
+// the labels the common job controller gives the pods it creates.
+func NewXGBoostPod(job *v1xgboost.XGBoostJob, rtype v1xgboost.XGBoostJobReplicaType, index int) *v1.Pod {
+	r := &ReconcileXGBoostJob{}
+	r.JobController.Controller = r
+	rt := strings.ToLower(string(rtype))
+	labels := r.GenLabels(job.Name)
+	labels[commonv1.ReplicaTypeLabel] = rt
+	labels[commonv1.ReplicaIndexLabel] = strconv.Itoa(index)
+	if r.IsMasterRole(job.Spec.XGBReplicaSpecs, commonv1.ReplicaType(rtype), index) {
+		labels[commonv1.JobRoleLabel] = "master"
+	}
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        common.GenGeneralName(job.Name, rt, strconv.Itoa(index)),
+			Namespace:   job.Namespace,
+			Labels:      labels,
+			Annotations: map[string]string{},
+		},
+	}
+}
+
 func NewXGBoostReplicaSpecTemplate() v1.PodTemplateSpec {
 	return v1.PodTemplateSpec{
 		Spec: v1.PodSpec{
@@ -83,6 +108,21 @@ func NewXGBoostReplicaSpecTemplate() v1.PodTemplateSpec {
 }
 
 func TestClusterSpec(t *testing.T) {
+	r := &ReconcileXGBoostJob{strategies: newClusterSpecStrategies("")}
+	lightGBMJob := func(worker int) *v1xgboost.XGBoostJob {
+		job := NewXGBoostJobWithMaster(worker)
+		job.Spec.Framework = v1xgboost.FrameworkLightGBM
+		return job
+	}
+	lightGBMWorkerOnlyJob := func(worker int) *v1xgboost.XGBoostJob {
+		job := NewXGoostJob(worker)
+		job.Spec.Framework = v1xgboost.FrameworkLightGBM
+		return job
+	}
+	restartedJob := NewXGBoostJobWithMaster(2)
+	restartedJob.Spec.RestartScope = v1xgboost.RestartScopeJob
+	restartedJob.Status.Attempt = 2
+
 	type tc struct {
 		job                 *v1xgboost.XGBoostJob
 		rt                  v1xgboost.XGBoostJobReplicaType
@@ -120,19 +160,97 @@ func TestClusterSpec(t *testing.T) {
 			index:               "1",
 			expectedClusterSpec: map[string]string{"WORLD_SIZE": "3", "MASTER_PORT": "9999", "RANK": "2", "MASTER_ADDR": "test-xgboostjob-master-0", "WORKER_PORT": "9999", "WORKER_ADDRS": "test-xgboostjob-worker-0,test-xgboostjob-worker-1"},
 		},
+		tc{
+			job:                 NewXGBoostJobWithMaster(0),
+			rt:                  v1xgboost.XGBoostReplicaTypeMaster,
+			index:               "0",
+			expectedClusterSpec: map[string]string{"DMLC_TRACKER_URI": "test-xgboostjob-master-0", "DMLC_TRACKER_PORT": "9999", "DMLC_NUM_WORKER": "1", "DMLC_TASK_ID": "0", "DMLC_ROLE": "worker", "XGBOOSTJOB_ATTEMPT": "0"},
+		},
+		tc{
+			job:                 restartedJob,
+			rt:                  v1xgboost.XGBoostReplicaTypeWorker,
+			index:               "0",
+			expectedClusterSpec: map[string]string{"RANK": "1", "XGBOOSTJOB_ATTEMPT": "2", "DMLC_NUM_ATTEMPT": "2"},
+		},
+		tc{
+			job:                 NewXGBoostJobWithMaster(2),
+			rt:                  v1xgboost.XGBoostReplicaTypeWorker,
+			index:               "1",
+			expectedClusterSpec: map[string]string{"DMLC_TRACKER_URI": "test-xgboostjob-master-0", "DMLC_TRACKER_PORT": "9999", "DMLC_NUM_WORKER": "3", "DMLC_TASK_ID": "2", "DMLC_ROLE": "worker"},
+		},
+		tc{
+			job:                 lightGBMJob(1),
+			rt:                  v1xgboost.XGBoostReplicaTypeMaster,
+			index:               "0",
+			expectedClusterSpec: map[string]string{"WORLD_SIZE": "2", "MASTER_PORT": "9999", "RANK": "0", "MASTER_ADDR": "test-xgboostjob-master-0", "WORKER_PORT": "9999", "WORKER_ADDRS": "test-xgboostjob-worker-0"},
+		},
+		tc{
+			job:                 lightGBMJob(2),
+			rt:                  v1xgboost.XGBoostReplicaTypeWorker,
+			index:               "0",
+			expectedClusterSpec: map[string]string{"WORLD_SIZE": "3", "MASTER_PORT": "9999", "RANK": "1", "MASTER_ADDR": "test-xgboostjob-master-0", "WORKER_PORT": "9999", "WORKER_ADDRS": "test-xgboostjob-worker-0,test-xgboostjob-worker-1", "LIGHTGBM_MACHINE_LIST_FILE": "/etc/xgboostjob/mlist.txt"},
+		},
+		tc{
+			job:                 NewXGoostJob(3),
+			rt:                  v1xgboost.XGBoostReplicaTypeWorker,
+			index:               "0",
+			expectedClusterSpec: map[string]string{"WORLD_SIZE": "3", "MASTER_PORT": "9999", "RANK": "0", "MASTER_ADDR": "test-xgboostjob-worker-0", "DMLC_TRACKER_URI": "test-xgboostjob-worker-0", "DMLC_TASK_ID": "0"},
+		},
+		tc{
+			job:                 NewXGoostJob(3),
+			rt:                  v1xgboost.XGBoostReplicaTypeWorker,
+			index:               "2",
+			expectedClusterSpec: map[string]string{"WORLD_SIZE": "3", "MASTER_PORT": "9999", "RANK": "2", "MASTER_ADDR": "test-xgboostjob-worker-0", "DMLC_TRACKER_URI": "test-xgboostjob-worker-0", "DMLC_TASK_ID": "2", "WORKER_ADDRS": "test-xgboostjob-worker-1,test-xgboostjob-worker-2"},
+		},
+		tc{
+			job:                 lightGBMWorkerOnlyJob(3),
+			rt:                  v1xgboost.XGBoostReplicaTypeWorker,
+			index:               "1",
+			expectedClusterSpec: map[string]string{"WORLD_SIZE": "3", "RANK": "1", "MASTER_ADDR": "test-xgboostjob-worker-0", "WORKER_ADDRS": "test-xgboostjob-worker-1,test-xgboostjob-worker-2"},
+		},
 	}
-	for _, c := range testCase {
+	for i, c := range testCase {
 		demoTemplateSpec := c.job.Spec.XGBReplicaSpecs[commonv1.ReplicaType(c.rt)].Template
-		if err := SetPodEnv(c.job, &demoTemplateSpec, string(c.rt), c.index); err != nil {
+		if err := r.SetClusterSpec(c.job, &demoTemplateSpec, string(c.rt), c.index); err != nil {
 			t.Errorf("Failed to set cluster spec: %v", err)
 		}
-		actual := demoTemplateSpec.Spec.Containers[0].Env
-		for _, env := range actual {
-			if val, ok := c.expectedClusterSpec[env.Name]; ok {
-				if val != env.Value {
-					t.Errorf("For name %s Got %s. Expected %s ", env.Name, env.Value, c.expectedClusterSpec[env.Name])
-				}
+		if attempt := demoTemplateSpec.Labels[labelXGBoostJobAttempt]; attempt != strconv.Itoa(int(c.job.Status.Attempt)) {
+			t.Errorf("Case %d: Got attempt label %s. Expected %d", i, attempt, c.job.Status.Attempt)
+		}
+		actual := map[string]string{}
+		for _, env := range demoTemplateSpec.Spec.Containers[0].Env {
+			actual[env.Name] = env.Value
+		}
+		for name, val := range c.expectedClusterSpec {
+			if actual[name] != val {
+				t.Errorf("Case %d: for name %s Got %s. Expected %s ", i, name, actual[name], val)
 			}
 		}
+		if _, ok := actual["DMLC_TRACKER_URI"]; ok && c.job.Spec.Framework == v1xgboost.FrameworkLightGBM {
+			t.Errorf("Case %d: got DMLC_TRACKER_URI for a LightGBM job", i)
+		}
+	}
+}
+
+func TestIsMasterRole(t *testing.T) {
+	r := &ReconcileXGBoostJob{}
+
+	type tc struct {
+		job      *v1xgboost.XGBoostJob
+		rt       v1xgboost.XGBoostJobReplicaType
+		index    int
+		expected bool
+	}
+	testCase := []tc{
+		tc{job: NewXGBoostJobWithMaster(2), rt: v1xgboost.XGBoostReplicaTypeMaster, index: 0, expected: true},
+		tc{job: NewXGBoostJobWithMaster(2), rt: v1xgboost.XGBoostReplicaTypeWorker, index: 0, expected: false},
+		tc{job: NewXGoostJob(2), rt: v1xgboost.XGBoostReplicaTypeWorker, index: 0, expected: true},
+		tc{job: NewXGoostJob(2), rt: v1xgboost.XGBoostReplicaTypeWorker, index: 1, expected: false},
+	}
+	for i, c := range testCase {
+		actual := r.IsMasterRole(c.job.Spec.XGBReplicaSpecs, commonv1.ReplicaType(c.rt), c.index)
+		if actual != c.expected {
+			t.Errorf("Case %d: Got %v. Expected %v", i, actual, c.expected)
+		}
 	}
 }
@@ -0,0 +1,94 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xgboostjob
+
+import (
+	"fmt"
+	"time"
+
+	v1xgboost "github.com/kubeflow/xgboost-operator/pkg/apis/xgboostjob/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// stalledReason is the reason of the failure of a job past its progress deadline.
+const stalledReason = "Stalled"
+
+// checkProgressDeadline fails the job if its coordinator has been running for longer than
+// the progress deadline of the job since its last progress, the later of its start, its
+// last training metrics and its last heartbeat. Its pods are then deleted according to
+// its CleanPodPolicy. It returns the time left before the deadline, at least a second, or
+// 0 if the job has no deadline or its coordinator is not running, and whether the job
+// was failed.
+func (r *ReconcileXGBoostJob) checkProgressDeadline(xgboostJob *v1xgboost.XGBoostJob) (time.Duration, bool, error) {
+	deadline := xgboostJob.Spec.ProgressDeadlineSeconds
+	if deadline == nil || isFinished(xgboostJob) {
+		return 0, false, nil
+	}
+	pods, err := r.getCoordinatorPods(xgboostJob)
+	if err != nil {
+		return 0, false, err
+	}
+	lastProgress := getLastProgressTime(xgboostJob, pods)
+	if lastProgress.IsZero() {
+		return 0, false, nil
+	}
+
+	window := time.Duration(*deadline) * time.Second
+	left := time.Until(lastProgress.Add(window))
+	if left > 0 {
+		if left < time.Second {
+			left = time.Second
+		}
+		return left, false, nil
+	}
+	msg := fmt.Sprintf("XGBoostJob %s is failed because it made no progress for %v since %s.",
+		xgboostJob.Name, window, lastProgress.UTC().Format(time.RFC3339))
+	return 0, true, r.failJob(xgboostJob, stalledReason, msg)
+}
+
+// getLastProgressTime returns when the running coordinator pod of the job last made
+// progress, or the zero time if no coordinator pod is running.
+func getLastProgressTime(xgboostJob *v1xgboost.XGBoostJob, pods []corev1.Pod) time.Time {
+	var pod *corev1.Pod
+	var lastProgress time.Time
+	for i := range pods {
+		for _, status := range pods[i].Status.ContainerStatuses {
+			if status.Name != v1xgboost.DefaultContainerName || status.State.Running == nil {
+				continue
+			}
+			// A coordinator restarted with the job replaces the previous one.
+			if startedAt := status.State.Running.StartedAt.Time; startedAt.After(lastProgress) {
+				pod, lastProgress = &pods[i], startedAt
+			}
+		}
+	}
+	if pod == nil {
+		return lastProgress
+	}
+
+	if training := xgboostJob.Status.TrainingMetrics; training != nil && training.ReportTime.After(lastProgress) {
+		lastProgress = training.ReportTime.Time
+	}
+	if value := pod.Annotations[v1xgboost.HeartbeatAnnotation]; value != "" {
+		heartbeat, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			log.Info("invalid heartbeat", "pod", pod.Name, "heartbeat", value)
+		} else if heartbeat.After(lastProgress) {
+			lastProgress = heartbeat
+		}
+	}
+	return lastProgress
+}
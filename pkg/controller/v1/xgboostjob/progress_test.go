@@ -0,0 +1,114 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xgboostjob
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	commonutil "github.com/kubeflow/common/pkg/util"
+	v1xgboost "github.com/kubeflow/xgboost-operator/pkg/apis/xgboostjob/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCheckProgressDeadline(t *testing.T) {
+	s := runtime.NewScheme()
+	if err := scheme.AddToScheme(s); err != nil {
+		t.Fatalf("Failed to build the scheme: %v", err)
+	}
+	if err := v1xgboost.AddToScheme(s); err != nil {
+		t.Fatalf("Failed to build the scheme: %v", err)
+	}
+
+	progressDeadlineSeconds := int64(600)
+	r := &ReconcileXGBoostJob{}
+	r.JobController.Controller = r
+	now := time.Now()
+	newCoordinator := func(job *v1xgboost.XGBoostJob, started time.Duration, heartbeat string) *corev1.Pod {
+		pod := NewXGBoostPod(job, v1xgboost.XGBoostReplicaTypeMaster, 0)
+		if heartbeat != "" {
+			pod.Annotations[v1xgboost.HeartbeatAnnotation] = heartbeat
+		}
+		state := corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ContainerCreating"}}
+		if started > 0 {
+			state = corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: metav1.NewTime(now.Add(-started))}}
+		}
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{{Name: v1xgboost.DefaultContainerName, State: state}}
+		return pod
+	}
+
+	withMetrics := NewXGBoostJobWithMaster(1)
+	withMetrics.Status.TrainingMetrics = &v1xgboost.TrainingMetricsStatus{Iteration: 42, ReportTime: metav1.NewTime(now.Add(-time.Minute))}
+
+	type tc struct {
+		job           *v1xgboost.XGBoostJob
+		started       time.Duration
+		heartbeat     string
+		min, max      time.Duration
+		expectStalled bool
+		noDeadline    bool
+	}
+	testCase := []tc{
+		// No deadline.
+		tc{job: NewXGBoostJobWithMaster(1), started: time.Hour, min: 0, max: 0, noDeadline: true},
+		// The coordinator is not running yet.
+		tc{job: NewXGBoostJobWithMaster(1), started: 0, min: 0, max: 0},
+		tc{job: NewXGBoostJobWithMaster(1), started: 5 * time.Minute, min: 294 * time.Second, max: 5 * time.Minute},
+		tc{job: NewXGBoostJobWithMaster(1), started: time.Hour, heartbeat: now.Add(-2 * time.Minute).UTC().Format(time.RFC3339),
+			min: 7*time.Minute + 55*time.Second, max: 8*time.Minute + time.Second},
+		tc{job: withMetrics, started: time.Hour, min: 8*time.Minute + 55*time.Second, max: 9 * time.Minute},
+		tc{job: NewXGBoostJobWithMaster(1), started: time.Hour, heartbeat: "yesterday", expectStalled: true},
+		tc{job: NewXGBoostJobWithMaster(1), started: 11 * time.Minute, expectStalled: true},
+	}
+	for i, c := range testCase {
+		job := c.job
+		if !c.noDeadline {
+			job.Spec.ProgressDeadlineSeconds = &progressDeadlineSeconds
+		}
+		recorder := record.NewFakeRecorder(10)
+		r.Recorder, r.recorder = recorder, recorder
+		r.Client = fake.NewFakeClientWithScheme(s, job.DeepCopy(), newCoordinator(job, c.started, c.heartbeat))
+
+		left, stalled, err := r.checkProgressDeadline(job)
+		if err != nil {
+			t.Fatalf("Case %d: failed to check the progress deadline: %v", i, err)
+		}
+		if stalled != c.expectStalled || left < c.min || left > c.max {
+			t.Errorf("Case %d: Got %v left and stalled %v. Expected between %v and %v and %v", i, left, stalled, c.min, c.max, c.expectStalled)
+		}
+
+		latest := &v1xgboost.XGBoostJob{}
+		if err := r.Get(context.Background(), types.NamespacedName{Namespace: job.Namespace, Name: job.Name}, latest); err != nil {
+			t.Fatalf("Case %d: failed to get the job: %v", i, err)
+		}
+		if failed := commonutil.IsFailed(latest.Status.JobStatus); failed != c.expectStalled {
+			t.Errorf("Case %d: Got failed %v. Expected %v", i, failed, c.expectStalled)
+		}
+		if c.expectStalled {
+			condition := latest.Status.Conditions[len(latest.Status.Conditions)-1]
+			if condition.Reason != stalledReason || len(recorder.Events) != 1 {
+				t.Errorf("Case %d: Got condition %v and %d events. Expected the reason %s and 1 event", i, condition, len(recorder.Events), stalledReason)
+			}
+		}
+	}
+}
@@ -0,0 +1,145 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xgboostjob
+
+import (
+	"fmt"
+
+	commonv1 "github.com/kubeflow/common/pkg/apis/common/v1"
+	logger "github.com/kubeflow/common/pkg/util"
+	v1xgboost "github.com/kubeflow/xgboost-operator/pkg/apis/xgboostjob/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// xgboostJobSuspendedReason is added in a job when its pods are deleted for spec.suspend.
+	xgboostJobSuspendedReason = "XGBoostJobSuspended"
+	// xgboostJobResumedReason is added in a job when spec.suspend is unset.
+	xgboostJobResumedReason = "XGBoostJobResumed"
+)
+
+// syncSuspension deletes the pods and services of a suspended job, and marks it
+// suspended. The pods of a resumed job are created again once those of the suspension
+// are gone, so that a pod failing on its way out is not taken for a failure. Its
+// StartTime is reset, the ActiveDeadlineSeconds of the job then count from its resumption.
+// It returns true as long as the pods of the job must not be reconciled.
+func (r *ReconcileXGBoostJob) syncSuspension(xgboostJob *v1xgboost.XGBoostJob) (bool, error) {
+	if isFinished(xgboostJob) || (!xgboostJob.Spec.Suspend && !isSuspended(xgboostJob)) {
+		return false, nil
+	}
+
+	pods, err := r.GetPodsForJob(xgboostJob)
+	if err != nil {
+		return true, err
+	}
+
+	if !xgboostJob.Spec.Suspend {
+		if len(pods) > 0 {
+			logger.LoggerForJob(xgboostJob).Infof("Waiting for %d pods of the suspension to be deleted", len(pods))
+			return true, nil
+		}
+		msg := fmt.Sprintf("XGBoostJob %s is resumed.", xgboostJob.Name)
+		r.recorder.Event(xgboostJob, corev1.EventTypeNormal, xgboostJobResumedReason, msg)
+		modified := xgboostJob.DeepCopy()
+		updateSuspendedCondition(&modified.Status.JobStatus, false, xgboostJobResumedReason, msg)
+		if err := r.patchStatus(xgboostJob, modified); err != nil {
+			return true, err
+		}
+		*xgboostJob = *modified
+		return false, nil
+	}
+
+	for _, pod := range pods {
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		if err := r.PodControl.DeletePod(pod.Namespace, pod.Name, xgboostJob); err != nil {
+			return true, err
+		}
+	}
+	services, err := r.GetServicesForJob(xgboostJob)
+	if err != nil {
+		return true, err
+	}
+	for _, service := range services {
+		if service.DeletionTimestamp != nil {
+			continue
+		}
+		if err := r.ServiceControl.DeleteService(service.Namespace, service.Name, xgboostJob); err != nil {
+			return true, err
+		}
+	}
+
+	if isSuspended(xgboostJob) {
+		return true, nil
+	}
+	msg := fmt.Sprintf("XGBoostJob %s is suspended.", xgboostJob.Name)
+	r.recorder.Event(xgboostJob, corev1.EventTypeNormal, xgboostJobSuspendedReason, msg)
+	modified := xgboostJob.DeepCopy()
+	updateSuspendedCondition(&modified.Status.JobStatus, true, xgboostJobSuspendedReason, msg)
+	modified.Status.StartTime = nil
+	for _, status := range modified.Status.ReplicaStatuses {
+		status.Active = 0
+	}
+	if err := r.patchStatus(xgboostJob, modified); err != nil {
+		return true, err
+	}
+	*xgboostJob = *modified
+	return true, nil
+}
+
+// isSuspended returns true if the job has a true Suspended condition.
+func isSuspended(xgboostJob *v1xgboost.XGBoostJob) bool {
+	for _, condition := range xgboostJob.Status.Conditions {
+		if condition.Type == v1xgboost.JobSuspended {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// updateSuspendedCondition sets the Suspended condition of the job. A suspended job is
+// not running.
+func updateSuspendedCondition(jobStatus *commonv1.JobStatus, suspended bool, reason, msg string) {
+	now := metav1.Now()
+	status := corev1.ConditionFalse
+	if suspended {
+		status = corev1.ConditionTrue
+	}
+	conditions := make([]commonv1.JobCondition, 0, len(jobStatus.Conditions)+1)
+	for _, condition := range jobStatus.Conditions {
+		if condition.Type == v1xgboost.JobSuspended {
+			continue
+		}
+		if suspended && condition.Type == commonv1.JobRunning && condition.Status == corev1.ConditionTrue {
+			condition.Status = corev1.ConditionFalse
+			condition.Reason = reason
+			condition.Message = msg
+			condition.LastUpdateTime = now
+			condition.LastTransitionTime = now
+		}
+		conditions = append(conditions, condition)
+	}
+	jobStatus.Conditions = append(conditions, commonv1.JobCondition{
+		Type:               v1xgboost.JobSuspended,
+		Status:             status,
+		Reason:             reason,
+		Message:            msg,
+		LastUpdateTime:     now,
+		LastTransitionTime: now,
+	})
+}
@@ -0,0 +1,128 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xgboostjob
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	commonv1 "github.com/kubeflow/common/pkg/apis/common/v1"
+	"github.com/kubeflow/common/pkg/controller.v1/control"
+	v1xgboost "github.com/kubeflow/xgboost-operator/pkg/apis/xgboostjob/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestSyncSuspension(t *testing.T) {
+	s := runtime.NewScheme()
+	if err := scheme.AddToScheme(s); err != nil {
+		t.Fatalf("Failed to build the scheme: %v", err)
+	}
+	if err := v1xgboost.AddToScheme(s); err != nil {
+		t.Fatalf("Failed to build the scheme: %v", err)
+	}
+
+	r := &ReconcileXGBoostJob{}
+	r.JobController.Controller = r
+	job := NewXGBoostJobWithMaster(1)
+	job.Spec.Suspend = true
+	job.Status.Conditions = []commonv1.JobCondition{{Type: commonv1.JobRunning, Status: corev1.ConditionTrue}}
+	job.Status.ReplicaStatuses = map[commonv1.ReplicaType]*commonv1.ReplicaStatus{
+		commonv1.ReplicaType(v1xgboost.XGBoostReplicaTypeMaster): {Active: 1, Failed: 1},
+	}
+	job.Status.RestartCounts = map[commonv1.ReplicaType]int32{commonv1.ReplicaType(v1xgboost.XGBoostReplicaTypeMaster): 1}
+	job.Status.LastCheckpoint = &v1xgboost.CheckpointStatus{Path: "/checkpoints/model-10", ReportTime: metav1.Now()}
+	now := metav1.Now()
+	job.Status.StartTime = &now
+	master := NewXGBoostPod(job, v1xgboost.XGBoostReplicaTypeMaster, 0)
+	pods := []runtime.Object{master, NewXGBoostPod(job, v1xgboost.XGBoostReplicaTypeWorker, 0)}
+	service := &corev1.Service{ObjectMeta: master.ObjectMeta}
+
+	getJob := func() *v1xgboost.XGBoostJob {
+		latest := &v1xgboost.XGBoostJob{}
+		if err := r.Get(context.Background(), types.NamespacedName{Namespace: job.Namespace, Name: job.Name}, latest); err != nil {
+			t.Fatalf("Failed to get the job: %v", err)
+		}
+		return latest
+	}
+	getCondition := func(job *v1xgboost.XGBoostJob, conditionType commonv1.JobConditionType) corev1.ConditionStatus {
+		for _, condition := range job.Status.Conditions {
+			if condition.Type == conditionType {
+				return condition.Status
+			}
+		}
+		return corev1.ConditionUnknown
+	}
+
+	// Suspending the job deletes its pods and services and keeps its restart counts and checkpoint.
+	podControl, serviceControl := &control.FakePodControl{}, &control.FakeServiceControl{}
+	r.PodControl, r.ServiceControl = podControl, serviceControl
+	recorder := record.NewFakeRecorder(10)
+	r.Recorder, r.recorder = recorder, recorder
+	r.Client = fake.NewFakeClientWithScheme(s, append(pods, job.DeepCopy(), service)...)
+	suspended, err := r.syncSuspension(job)
+	if err != nil {
+		t.Fatalf("Failed to suspend the job: %v", err)
+	}
+	if !suspended || len(podControl.DeletePodName) != 2 || len(serviceControl.DeleteServiceName) != 1 {
+		t.Errorf("Got suspended %v, deleted pods %v and services %v. Expected 2 pods and 1 service deleted",
+			suspended, podControl.DeletePodName, serviceControl.DeleteServiceName)
+	}
+	latest := getJob()
+	if getCondition(latest, v1xgboost.JobSuspended) != corev1.ConditionTrue || getCondition(latest, commonv1.JobRunning) != corev1.ConditionFalse {
+		t.Errorf("Got conditions %v. Expected the job suspended and not running", latest.Status.Conditions)
+	}
+	if latest.Status.StartTime != nil || latest.Status.ReplicaStatuses[commonv1.ReplicaType(v1xgboost.XGBoostReplicaTypeMaster)].Active != 0 {
+		t.Errorf("Got start time %v and replica statuses %v. Expected no start time and no active replicas",
+			latest.Status.StartTime, latest.Status.ReplicaStatuses)
+	}
+	if !reflect.DeepEqual(latest.Status.RestartCounts, job.Status.RestartCounts) || latest.Status.LastCheckpoint == nil || latest.Status.LastCheckpoint.Path != job.Status.LastCheckpoint.Path {
+		t.Errorf("Got restart counts %v and checkpoint %v. Expected them kept", latest.Status.RestartCounts, latest.Status.LastCheckpoint)
+	}
+	if len(recorder.Events) != 1 {
+		t.Errorf("Got %d events. Expected 1", len(recorder.Events))
+	}
+
+	// A resumed job waits for the pods of the suspension to be deleted.
+	job.Spec.Suspend = false
+	r.Client = fake.NewFakeClientWithScheme(s, append(pods, job.DeepCopy())...)
+	suspended, err = r.syncSuspension(job)
+	if err != nil {
+		t.Fatalf("Failed to resume the job: %v", err)
+	}
+	if !suspended || !isSuspended(getJob()) {
+		t.Errorf("Got suspended %v with pods left. Expected the job to stay suspended", suspended)
+	}
+
+	r.Client = fake.NewFakeClientWithScheme(s, job.DeepCopy())
+	suspended, err = r.syncSuspension(job)
+	if err != nil {
+		t.Fatalf("Failed to resume the job: %v", err)
+	}
+	latest = getJob()
+	if suspended || getCondition(latest, v1xgboost.JobSuspended) != corev1.ConditionFalse {
+		t.Errorf("Got suspended %v and conditions %v. Expected the job resumed", suspended, latest.Status.Conditions)
+	}
+	if !reflect.DeepEqual(latest.Status.RestartCounts, job.Status.RestartCounts) || latest.Status.LastCheckpoint == nil || latest.Status.LastCheckpoint.Path != job.Status.LastCheckpoint.Path {
+		t.Errorf("Got restart counts %v and checkpoint %v. Expected them kept", latest.Status.RestartCounts, latest.Status.LastCheckpoint)
+	}
+}
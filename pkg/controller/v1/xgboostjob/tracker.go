@@ -0,0 +1,71 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xgboostjob
+
+import (
+	"fmt"
+
+	v1xgboost "github.com/kubeflow/xgboost-operator/pkg/apis/xgboostjob/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// trackerContainerName is the name of the Rabit tracker sidecar.
+	trackerContainerName = "xgboost-tracker"
+	// trackerCommand is the path of the tracker binary in the operator image.
+	trackerCommand = "/root/tracker"
+)
+
+// isTrackerSidecarEnabled returns true if the job asks for the built-in tracker.
+func isTrackerSidecarEnabled(job *v1xgboost.XGBoostJob) bool {
+	return job.Spec.RabitTracker != nil && job.Spec.RabitTracker.Sidecar
+}
+
+// setTrackerSidecar adds the Rabit tracker container to the coordinator pod. It must be
+// called before the DMLC env is set, which gives the tracker its port and number of workers.
+func setTrackerSidecar(job *v1xgboost.XGBoostJob, podTemplate *corev1.PodTemplateSpec, defaultImage string) error {
+	for _, container := range podTemplate.Spec.Containers {
+		if container.Name == trackerContainerName {
+			return nil
+		}
+	}
+
+	image := job.Spec.RabitTracker.Image
+	if image == "" {
+		image = defaultImage
+	}
+	if image == "" {
+		return fmt.Errorf("XGBoostJob %s asks for a tracker sidecar but no tracker image is set", job.Name)
+	}
+
+	// The tracker annotates its pod with the training metrics printed by the workers.
+	podTemplate.Spec.Containers = append(podTemplate.Spec.Containers, corev1.Container{
+		Name:    trackerContainerName,
+		Image:   image,
+		Command: []string{trackerCommand},
+		Env:     podRefEnv(),
+	})
+	return nil
+}
+
+// podRefEnv returns the variables of the name and namespace of the pod, which the tracker
+// annotates with the training metrics.
+func podRefEnv() []corev1.EnvVar {
+	return []corev1.EnvVar{
+		{Name: "POD_NAME", ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.name"}}},
+		{Name: "POD_NAMESPACE", ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.namespace"}}},
+	}
+}
@@ -0,0 +1,94 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xgboostjob
+
+import (
+	"testing"
+
+	commonv1 "github.com/kubeflow/common/pkg/apis/common/v1"
+	v1xgboost "github.com/kubeflow/xgboost-operator/pkg/apis/xgboostjob/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestTrackerSidecar(t *testing.T) {
+	r := &ReconcileXGBoostJob{strategies: newClusterSpecStrategies("xgboost-operator:latest")}
+
+	withSidecar := NewXGBoostJobWithMaster(2)
+	withSidecar.Spec.RabitTracker = &v1xgboost.RabitTrackerSpec{Sidecar: true}
+
+	withImage := NewXGBoostJobWithMaster(2)
+	withImage.Spec.RabitTracker = &v1xgboost.RabitTrackerSpec{Sidecar: true, Image: "tracker:v1"}
+
+	// Worker-0 runs the tracker of a job without Master.
+	workerOnly := NewXGoostJob(3)
+	workerOnly.Spec.RabitTracker = &v1xgboost.RabitTrackerSpec{Sidecar: true}
+
+	type tc struct {
+		job           *v1xgboost.XGBoostJob
+		rt            v1xgboost.XGBoostJobReplicaType
+		index         string
+		expectedImage string
+	}
+	testCase := []tc{
+		tc{job: NewXGBoostJobWithMaster(2), rt: v1xgboost.XGBoostReplicaTypeMaster, index: "0", expectedImage: ""},
+		tc{job: withSidecar, rt: v1xgboost.XGBoostReplicaTypeWorker, index: "0", expectedImage: ""},
+		tc{job: withSidecar, rt: v1xgboost.XGBoostReplicaTypeMaster, index: "0", expectedImage: "xgboost-operator:latest"},
+		tc{job: withImage, rt: v1xgboost.XGBoostReplicaTypeMaster, index: "0", expectedImage: "tracker:v1"},
+		tc{job: workerOnly, rt: v1xgboost.XGBoostReplicaTypeWorker, index: "0", expectedImage: "xgboost-operator:latest"},
+		tc{job: workerOnly, rt: v1xgboost.XGBoostReplicaTypeWorker, index: "1", expectedImage: ""},
+	}
+	for i, c := range testCase {
+		podTemplate := c.job.Spec.XGBReplicaSpecs[commonv1.ReplicaType(c.rt)].Template.DeepCopy()
+		if err := r.SetClusterSpec(c.job, podTemplate, string(c.rt), c.index); err != nil {
+			t.Fatalf("Case %d: failed to set cluster spec: %v", i, err)
+		}
+
+		var tracker *corev1.Container
+		for j := range podTemplate.Spec.Containers {
+			if podTemplate.Spec.Containers[j].Name == trackerContainerName {
+				tracker = &podTemplate.Spec.Containers[j]
+			}
+		}
+		if c.expectedImage == "" {
+			if tracker != nil {
+				t.Errorf("Case %d: got tracker sidecar %v. Expected none", i, tracker)
+			}
+			continue
+		}
+		if tracker == nil {
+			t.Fatalf("Case %d: got no tracker sidecar", i)
+		}
+		if tracker.Image != c.expectedImage {
+			t.Errorf("Case %d: got tracker image %s. Expected %s", i, tracker.Image, c.expectedImage)
+		}
+		envs := map[string]string{}
+		for _, env := range tracker.Env {
+			envs[env.Name] = env.Value
+		}
+		if envs["DMLC_TRACKER_PORT"] != "9999" || envs["DMLC_NUM_WORKER"] != "3" {
+			t.Errorf("Case %d: got tracker env %v", i, envs)
+		}
+		if env := tracker.Env[0]; env.Name != "POD_NAME" || env.ValueFrom == nil || env.ValueFrom.FieldRef.FieldPath != "metadata.name" {
+			t.Errorf("Case %d: got tracker env %v. Expected the name of the pod", i, env)
+		}
+	}
+
+	noImage := &ReconcileXGBoostJob{strategies: newClusterSpecStrategies("")}
+	podTemplate := withSidecar.Spec.XGBReplicaSpecs[commonv1.ReplicaType(v1xgboost.XGBoostReplicaTypeMaster)].Template.DeepCopy()
+	if err := noImage.SetClusterSpec(withSidecar, podTemplate, "master", "0"); err == nil {
+		t.Errorf("Expected an error without tracker image")
+	}
+}
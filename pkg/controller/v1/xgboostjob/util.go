@@ -23,6 +23,7 @@ import (
 	"time"
 
 	commonv1 "github.com/kubeflow/common/pkg/apis/common/v1"
+	commonutil "github.com/kubeflow/common/pkg/util"
 	v1xgboost "github.com/kubeflow/xgboost-operator/pkg/apis/xgboostjob/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	kubeclientset "k8s.io/client-go/kubernetes"
@@ -54,14 +55,67 @@ func getDelegatingReader(c client.Client) (*client.DelegatingReader, error) {
 	return dr, nil
 }
 
+// isFinished returns true if the job has succeeded or failed.
+func isFinished(job *v1xgboost.XGBoostJob) bool {
+	return commonutil.IsSucceeded(job.Status.JobStatus) || commonutil.IsFailed(job.Status.JobStatus)
+}
+
+// timeUntilActiveDeadline returns the time left before the job is past its
+// ActiveDeadlineSeconds, at least a second, or 0 if it has none or is finished.
+func timeUntilActiveDeadline(job *v1xgboost.XGBoostJob) time.Duration {
+	activeDeadlineSeconds := job.Spec.RunPolicy.ActiveDeadlineSeconds
+	if activeDeadlineSeconds == nil || isFinished(job) {
+		return 0
+	}
+	start := time.Now()
+	if job.Status.StartTime != nil {
+		start = job.Status.StartTime.Time
+	}
+	left := time.Until(start.Add(time.Duration(*activeDeadlineSeconds) * time.Second))
+	if left < time.Second {
+		return time.Second
+	}
+	return left
+}
+
+// earliest returns the shortest of the given durations which are not 0, or 0 if all are.
+func earliest(durations ...time.Duration) time.Duration {
+	var min time.Duration
+	for _, d := range durations {
+		if d > 0 && (min == 0 || d < min) {
+			min = d
+		}
+	}
+	return min
+}
+
 func computeMasterAddr(jobName, rtype, index string) string {
 	n := jobName + "-" + rtype + "-" + index
 	return strings.Replace(n, "/", "-", -1)
 }
 
+// isWorkerOnly returns true if the job has no Master replica. Worker-0 then coordinates
+// the job: it gets rank 0, runs the tracker and its completion is the job success.
+func isWorkerOnly(replicas map[commonv1.ReplicaType]*commonv1.ReplicaSpec) bool {
+	_, ok := replicas[commonv1.ReplicaType(v1xgboost.XGBoostReplicaTypeMaster)]
+	return !ok
+}
+
+// getCoordinatorType returns the replica type whose replica 0 coordinates the job.
+func getCoordinatorType(replicas map[commonv1.ReplicaType]*commonv1.ReplicaSpec) v1xgboost.XGBoostJobReplicaType {
+	if isWorkerOnly(replicas) {
+		return v1xgboost.XGBoostReplicaTypeWorker
+	}
+	return v1xgboost.XGBoostReplicaTypeMaster
+}
+
 // GetPortFromXGBoostJob gets the port of xgboost container.
 func GetPortFromXGBoostJob(job *v1xgboost.XGBoostJob, rtype v1xgboost.XGBoostJobReplicaType) (int32, error) {
-	containers := job.Spec.XGBReplicaSpecs[commonv1.ReplicaType(rtype)].Template.Spec.Containers
+	spec, ok := job.Spec.XGBReplicaSpecs[commonv1.ReplicaType(rtype)]
+	if !ok || spec == nil {
+		return -1, fmt.Errorf("XGBoostJob %s has no %s replica", job.Name, rtype)
+	}
+	containers := spec.Template.Spec.Containers
 	for _, container := range containers {
 		if container.Name == v1xgboost.DefaultContainerName {
 			ports := container.Ports
@@ -125,13 +179,33 @@ func homeDir() string {
 
 func isGangSchedulerSet(replicas map[commonv1.ReplicaType]*commonv1.ReplicaSpec) bool {
 	for _, spec := range replicas {
-		if spec.Template.Spec.SchedulerName != "" && spec.Template.Spec.SchedulerName == gangSchedulerName {
+		if spec == nil {
+			continue
+		}
+		schedulerName := spec.Template.Spec.SchedulerName
+		if schedulerName == gangSchedulerName || schedulerName == volcanoSchedulerName {
 			return true
 		}
 	}
 	return false
 }
 
+// isGangSchedulingEnabled returns true if the job asks for gang scheduling, either
+// explicitly through RunPolicy.SchedulingPolicy or by a gang scheduler name in any
+// replica template.
+func isGangSchedulingEnabled(job *v1xgboost.XGBoostJob) bool {
+	return job.Spec.RunPolicy.SchedulingPolicy != nil || isGangSchedulerSet(job.Spec.XGBReplicaSpecs)
+}
+
+// computeMinAvailable returns the MinMember of the job PodGroup. It is the
+// MinAvailable of the scheduling policy if set, otherwise all replicas of the job.
+func computeMinAvailable(job *v1xgboost.XGBoostJob) int32 {
+	if policy := job.Spec.RunPolicy.SchedulingPolicy; policy != nil && policy.MinAvailable != nil {
+		return *policy.MinAvailable
+	}
+	return computeTotalReplicas(job)
+}
+
 // FakeWorkQueue implements RateLimitingInterface but actually does nothing.
 type FakeWorkQueue struct{}
 
@@ -0,0 +1,126 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xgboostjob
+
+import (
+	"testing"
+	"time"
+
+	commonv1 "github.com/kubeflow/common/pkg/apis/common/v1"
+	commonutil "github.com/kubeflow/common/pkg/util"
+	v1xgboost "github.com/kubeflow/xgboost-operator/pkg/apis/xgboostjob/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGangScheduling(t *testing.T) {
+	minAvailable := int32(2)
+
+	withScheduler := func(name string) *v1xgboost.XGBoostJob {
+		job := NewXGBoostJobWithMaster(2)
+		job.Spec.XGBReplicaSpecs[commonv1.ReplicaType(v1xgboost.XGBoostReplicaTypeWorker)].Template.Spec.SchedulerName = name
+		return job
+	}
+	withPolicy := NewXGBoostJobWithMaster(2)
+	withPolicy.Spec.RunPolicy.SchedulingPolicy = &commonv1.SchedulingPolicy{MinAvailable: &minAvailable}
+
+	type tc struct {
+		job                  *v1xgboost.XGBoostJob
+		expectedEnabled      bool
+		expectedMinAvailable int32
+	}
+	testCase := []tc{
+		tc{
+			job:                  NewXGBoostJobWithMaster(2),
+			expectedEnabled:      false,
+			expectedMinAvailable: 3,
+		},
+		tc{
+			job:                  withScheduler("default-scheduler"),
+			expectedEnabled:      false,
+			expectedMinAvailable: 3,
+		},
+		tc{
+			job:                  withScheduler(gangSchedulerName),
+			expectedEnabled:      true,
+			expectedMinAvailable: 3,
+		},
+		tc{
+			job:                  withScheduler(volcanoSchedulerName),
+			expectedEnabled:      true,
+			expectedMinAvailable: 3,
+		},
+		tc{
+			job:                  withPolicy,
+			expectedEnabled:      true,
+			expectedMinAvailable: 2,
+		},
+	}
+	for _, c := range testCase {
+		if enabled := isGangSchedulingEnabled(c.job); enabled != c.expectedEnabled {
+			t.Errorf("Got gang scheduling %v. Expected %v", enabled, c.expectedEnabled)
+		}
+		if actual := computeMinAvailable(c.job); actual != c.expectedMinAvailable {
+			t.Errorf("Got MinAvailable %d. Expected %d", actual, c.expectedMinAvailable)
+		}
+	}
+}
+
+func TestTimeUntilActiveDeadline(t *testing.T) {
+	activeDeadlineSeconds := int64(60)
+	withDeadline := func(started time.Duration) *v1xgboost.XGBoostJob {
+		job := NewXGBoostJobWithMaster(2)
+		job.Spec.RunPolicy.ActiveDeadlineSeconds = &activeDeadlineSeconds
+		start := metav1.NewTime(time.Now().Add(-started))
+		job.Status.StartTime = &start
+		return job
+	}
+	finished := withDeadline(10 * time.Second)
+	commonutil.UpdateJobConditions(&finished.Status.JobStatus, commonv1.JobSucceeded, xgboostJobSucceededReason, "")
+
+	type tc struct {
+		job      *v1xgboost.XGBoostJob
+		min, max time.Duration
+	}
+	testCase := []tc{
+		tc{job: NewXGBoostJobWithMaster(2), min: 0, max: 0},
+		tc{job: withDeadline(10 * time.Second), min: 49 * time.Second, max: 50 * time.Second},
+		tc{job: withDeadline(2 * time.Minute), min: time.Second, max: time.Second},
+		tc{job: finished, min: 0, max: 0},
+	}
+	for i, c := range testCase {
+		actual := timeUntilActiveDeadline(c.job)
+		if actual < c.min || actual > c.max {
+			t.Errorf("Case %d: Got %v. Expected between %v and %v", i, actual, c.min, c.max)
+		}
+	}
+}
+
+func TestEarliest(t *testing.T) {
+	type tc struct {
+		durations []time.Duration
+		expected  time.Duration
+	}
+	testCase := []tc{
+		tc{durations: []time.Duration{0, 0}, expected: 0},
+		tc{durations: []time.Duration{0, time.Minute}, expected: time.Minute},
+		tc{durations: []time.Duration{time.Hour, time.Minute}, expected: time.Minute},
+	}
+	for i, c := range testCase {
+		if actual := earliest(c.durations...); actual != c.expected {
+			t.Errorf("Case %d: Got %v. Expected %v", i, actual, c.expected)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xgboostjob
+
+import (
+	"strconv"
+
+	v1xgboost "github.com/kubeflow/xgboost-operator/pkg/apis/xgboostjob/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// xgboostStrategy sets up distributed XGBoost. Every replica joins the Rabit ring as a
+// worker, and the tracker runs on the port of the coordinator.
+type xgboostStrategy struct {
+	// trackerImage is the default image of the Rabit tracker sidecar.
+	trackerImage string
+}
+
+var _ ClusterSpecStrategy = &xgboostStrategy{}
+
+// SetClusterSpec sets the env read by the XGBoost collective (Rabit) client, and adds
+// the tracker sidecar to the coordinator pod if the job asks for it.
+func (s *xgboostStrategy) SetClusterSpec(job *v1xgboost.XGBoostJob, podTemplate *corev1.PodTemplateSpec, cluster *ClusterSpec) error {
+	if cluster.IsCoordinator() && isTrackerSidecarEnabled(job) {
+		if err := setTrackerSidecar(job, podTemplate, s.trackerImage); err != nil {
+			return err
+		}
+	}
+
+	for i := range podTemplate.Spec.Containers {
+		podTemplate.Spec.Containers[i].Env = append(podTemplate.Spec.Containers[i].Env, corev1.EnvVar{
+			Name:  "DMLC_TRACKER_URI",
+			Value: cluster.MasterAddr,
+		})
+		podTemplate.Spec.Containers[i].Env = append(podTemplate.Spec.Containers[i].Env, corev1.EnvVar{
+			Name:  "DMLC_TRACKER_PORT",
+			Value: strconv.Itoa(int(cluster.MasterPort)),
+		})
+		podTemplate.Spec.Containers[i].Env = append(podTemplate.Spec.Containers[i].Env, corev1.EnvVar{
+			Name:  "DMLC_NUM_WORKER",
+			Value: strconv.Itoa(int(cluster.WorldSize)),
+		})
+		podTemplate.Spec.Containers[i].Env = append(podTemplate.Spec.Containers[i].Env, corev1.EnvVar{
+			Name:  "DMLC_TASK_ID",
+			Value: strconv.Itoa(cluster.Rank),
+		})
+		podTemplate.Spec.Containers[i].Env = append(podTemplate.Spec.Containers[i].Env, corev1.EnvVar{
+			Name:  "DMLC_ROLE",
+			Value: "worker",
+		})
+		podTemplate.Spec.Containers[i].Env = append(podTemplate.Spec.Containers[i].Env, corev1.EnvVar{
+			Name:  "DMLC_NUM_ATTEMPT",
+			Value: strconv.Itoa(int(cluster.Attempt)),
+		})
+	}
+	return nil
+}
+
+// ConfigMapData returns nil, XGBoost needs no file.
+func (s *xgboostStrategy) ConfigMapData(job *v1xgboost.XGBoostJob) (map[string]string, error) {
+	return nil, nil
+}
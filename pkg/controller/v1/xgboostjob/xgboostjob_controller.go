@@ -15,19 +15,21 @@ package xgboostjob
 import (
 	"context"
 	"flag"
+	"fmt"
 	commonv1 "github.com/kubeflow/common/pkg/apis/common/v1"
 	"github.com/kubeflow/common/pkg/controller.v1/common"
 	"github.com/kubeflow/common/pkg/controller.v1/control"
 	"github.com/kubeflow/common/pkg/controller.v1/expectation"
 	v1xgboost "github.com/kubeflow/xgboost-operator/pkg/apis/xgboostjob/v1"
+	"github.com/kubeflow/xgboost-operator/pkg/apis/xgboostjob/validation"
 	corev1 "k8s.io/api/core/v1"
 	"path/filepath"
+	"strconv"
 
 	"github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
-	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/tools/record"
@@ -35,6 +37,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
@@ -44,14 +47,15 @@ import (
 const (
 	controllerName      = "xgboostjob-operator"
 	labelXGBoostJobRole = "xgboostjob-job-role"
-	// gang scheduler name.
-	gangSchedulerName = "kube-batch"
+	// labelXGBoostJobAttempt is the label of the attempt a pod belongs to.
+	labelXGBoostJobAttempt = "xgboostjob-attempt"
+	// gang scheduler names.
+	gangSchedulerName    = "kube-batch"
+	volcanoSchedulerName = "volcano"
+	// gangSchedulingPodGroupAnnotation is the annotation of the PodGroup of a pod.
+	gangSchedulingPodGroupAnnotation = "scheduling.k8s.io/group-name"
 )
 
-var (
-	defaultTTLseconds     = int32(100)
-	defaultCleanPodPolicy = commonv1.CleanPodPolicyNone
-)
 var log = logf.Log.WithName("controller")
 
 /**
@@ -88,6 +92,11 @@ func newReconciler(mgr manager.Manager) reconcile.Reconciler {
 	flag.Parse()
 
 	mode = flag.Lookup("mode").Value.(flag.Getter).Get().(string)
+	var trackerImage string
+	if f := flag.Lookup("tracker-image"); f != nil {
+		trackerImage = f.Value.String()
+	}
+	r.strategies = newClusterSpecStrategies(trackerImage)
 	if mode == "local" {
 		log.Info("Running controller in local mode, using kubeconfig file")
 		/// TODO, add the master url and kubeconfigpath with user input
@@ -117,18 +126,12 @@ func newReconciler(mgr manager.Manager) reconcile.Reconciler {
 		log.Info("Error building kubeclientset: %s", err.Error())
 	}
 
-	// Create Informer factory
-	xgboostjob := &v1xgboost.XGBoostJob{}
-
-	gangScheduling := isGangSchedulerSet(xgboostjob.Spec.XGBReplicaSpecs)
-
-	log.Info("gang scheduling is set: ", "gangscheduling", gangScheduling)
-
 	// Initialize common job controller with components we only need.
+	// Gang scheduling is decided per job by Reconcile and SetClusterSpec.
 	r.JobController = common.JobController{
 		Controller:       r,
 		Expectations:     expectation.NewControllerExpectations(),
-		Config:           common.JobControllerConfiguration{EnableGangScheduling: gangScheduling},
+		Config:           common.JobControllerConfiguration{},
 		WorkQueue:        &FakeWorkQueue{},
 		Recorder:         r.recorder,
 		KubeClientSet:    kubeClientSet,
@@ -156,6 +159,14 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		return err
 	}
 
+	// Watch for the XGBoostJobs other jobs take their model from
+	err = c.Watch(&source.Kind{Type: &v1xgboost.XGBoostJob{}}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: newModelConsumersMapper(mgr.GetClient()),
+	})
+	if err != nil {
+		return err
+	}
+
 	//inject watching for  xgboostjob related pod
 	err = c.Watch(&source.Kind{Type: &corev1.Pod{}}, &handler.EnqueueRequestForOwner{
 		IsController: true,
@@ -178,7 +189,8 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		return err
 	}
 
-	return nil
+	// Expose the training metrics of the jobs on the metrics endpoint of the manager
+	return metrics.Registry.Register(&trainingMetricsCollector{reader: mgr.GetClient()})
 }
 
 var _ reconcile.Reconciler = &ReconcileXGBoostJob{}
@@ -189,6 +201,8 @@ type ReconcileXGBoostJob struct {
 	client.Client
 	scheme   *runtime.Scheme
 	recorder record.EventRecorder
+	// strategies set up the pods of each framework.
+	strategies map[v1xgboost.Framework]ClusterSpecStrategy
 }
 
 // Reconcile reads that state of the cluster for a XGBoostJob object and makes changes based on the state read
@@ -197,6 +211,7 @@ type ReconcileXGBoostJob struct {
 // Automatically generate RBAC rules to allow the Controller to read and write Deployments
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apps,resources=deployments/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=xgboostjob.kubeflow.org,resources=xgboostjobs,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=xgboostjob.kubeflow.org,resources=xgboostjobs/status,verbs=get;update;patch
 func (r *ReconcileXGBoostJob) Reconcile(request reconcile.Request) (reconcile.Result, error) {
@@ -221,18 +236,91 @@ func (r *ReconcileXGBoostJob) Reconcile(request reconcile.Request) (reconcile.Re
 			"sync", needSync, "deleted", xgboostjob.DeletionTimestamp != nil)
 		return reconcile.Result{}, nil
 	}
-	// Set default priorities for xgboost job
-	scheme.Scheme.Default(xgboostjob)
+	// Set defaults for xgboost job in memory, in case it was admitted without the defaulting webhook.
+	r.scheme.Default(xgboostjob)
+
+	if !isFinished(xgboostjob) {
+		// Validate the job in case it was admitted without the validating webhook. A job
+		// that has started is not failed by the checks added since it was admitted.
+		if xgboostjob.Status.StartTime == nil {
+			if errs := validation.ValidateV1XGBoostJob(xgboostjob); len(errs) > 0 {
+				return reconcile.Result{}, r.failInvalidJob(xgboostjob, errs.ToAggregate())
+			}
+		}
+		if err = r.reconcileConfigMap(xgboostjob); err != nil {
+			logrus.Warnf("Reconcile ConfigMap for XGBoost Job %s error %v", xgboostjob.Name, err)
+			return reconcile.Result{}, err
+		}
+	}
+
+	// The pods of a job taking the model of another job wait for it to succeed.
+	ready, err := r.resolveModelSource(xgboostjob)
+	if err != nil {
+		logrus.Warnf("Resolve model source for XGBoost Job %s error %v", xgboostjob.Name, err)
+		return reconcile.Result{}, err
+	}
+	if !ready {
+		// Recheck once the referenced job is overdue, in case it is never created.
+		return reconcile.Result{RequeueAfter: timeUntilModelSourceDeadline(xgboostjob)}, nil
+	}
+
+	if err = r.syncLastCheckpoint(xgboostjob); err != nil {
+		logrus.Warnf("Sync last checkpoint for XGBoost Job %s error %v", xgboostjob.Name, err)
+		return reconcile.Result{}, err
+	}
+
+	if err = r.syncTrainingMetrics(xgboostjob); err != nil {
+		logrus.Warnf("Sync training metrics for XGBoost Job %s error %v", xgboostjob.Name, err)
+		return reconcile.Result{}, err
+	}
+
+	if err = r.syncPredictions(xgboostjob); err != nil {
+		logrus.Warnf("Sync predictions for XGBoost Job %s error %v", xgboostjob.Name, err)
+		return reconcile.Result{}, err
+	}
+
+	// A suspended job keeps no pods until it is resumed.
+	suspended, err := r.syncSuspension(xgboostjob)
+	if err != nil {
+		logrus.Warnf("Sync suspension for XGBoost Job %s error %v", xgboostjob.Name, err)
+		return reconcile.Result{}, err
+	}
+	if suspended {
+		return reconcile.Result{}, nil
+	}
 
-	// Use common to reconcile the job related pod and service
-	err = r.ReconcileJobs(xgboostjob, xgboostjob.Spec.XGBReplicaSpecs, xgboostjob.Status.JobStatus, &xgboostjob.Spec.RunPolicy)
+	// A job whose coordinator makes no progress is failed as stalled.
+	progressDeadline, stalled, err := r.checkProgressDeadline(xgboostjob)
+	if err != nil {
+		logrus.Warnf("Check progress deadline for XGBoost Job %s error %v", xgboostjob.Name, err)
+		return reconcile.Result{}, err
+	}
+	if stalled {
+		return reconcile.Result{}, nil
+	}
+
+	if err = r.syncDataDownloads(xgboostjob); err != nil {
+		logrus.Warnf("Sync data downloads for XGBoost Job %s error %v", xgboostjob.Name, err)
+		return reconcile.Result{}, err
+	}
+
+	if err = r.syncGangScheduling(xgboostjob); err != nil {
+		logrus.Warnf("Sync gang scheduling for XGBoost Job %s error %v", xgboostjob.Name, err)
+		return reconcile.Result{}, err
+	}
+
+	// Use common to reconcile the job related pod and service. It fills in the replica
+	// statuses of a copy of the status, the job keeps the status the patch is made from.
+	jobStatus := xgboostjob.Status.JobStatus.DeepCopy()
+	err = r.ReconcileJobs(xgboostjob, xgboostjob.Spec.XGBReplicaSpecs, *jobStatus, &xgboostjob.Spec.RunPolicy)
 
 	if err != nil {
 		logrus.Warnf("Reconcile XGBoost Job error %v", err)
 		return reconcile.Result{}, err
 	}
 
-	return reconcile.Result{}, err
+	// Come back at the active or progress deadline, the job may get no other event by then.
+	return reconcile.Result{RequeueAfter: earliest(timeUntilActiveDeadline(xgboostjob), progressDeadline)}, nil
 }
 
 func (r *ReconcileXGBoostJob) ControllerName() string {
@@ -263,12 +351,52 @@ func (r *ReconcileXGBoostJob) GetJobRoleKey() string {
 	return labelXGBoostJobRole
 }
 
+// IsMasterRole returns true for the coordinator of the job, Master-0, or Worker-0 if
+// the job has no Master.
 func (r *ReconcileXGBoostJob) IsMasterRole(replicas map[commonv1.ReplicaType]*commonv1.ReplicaSpec,
 	rtype commonv1.ReplicaType, index int) bool {
+	if isWorkerOnly(replicas) {
+		return string(rtype) == string(v1xgboost.XGBoostReplicaTypeWorker) && index == 0
+	}
 	return string(rtype) == string(v1xgboost.XGBoostReplicaTypeMaster)
 }
 
 // SetClusterSpec sets the cluster spec for the pod
 func (r *ReconcileXGBoostJob) SetClusterSpec(job interface{}, podTemplate *corev1.PodTemplateSpec, rtype, index string) error {
-	return SetPodEnv(job, podTemplate, rtype, index)
+	xgboostjob, ok := job.(*v1xgboost.XGBoostJob)
+	if !ok {
+		return fmt.Errorf("%+v is not a type of XGBoostJob", xgboostjob)
+	}
+	strategy, err := r.getClusterSpecStrategy(xgboostjob)
+	if err != nil {
+		return err
+	}
+	cluster, err := newClusterSpec(xgboostjob, rtype, index)
+	if err != nil {
+		return err
+	}
+	data, err := genConfigMapData(xgboostjob, strategy)
+	if err != nil {
+		return err
+	}
+
+	if podTemplate.Labels == nil {
+		podTemplate.Labels = map[string]string{}
+	}
+	podTemplate.Labels[labelXGBoostJobAttempt] = strconv.Itoa(int(cluster.Attempt))
+	if err := SetPodEnv(job, podTemplate, rtype, index); err != nil {
+		return err
+	}
+	if err := strategy.SetClusterSpec(xgboostjob, podTemplate, cluster); err != nil {
+		return err
+	}
+	r.setPodGroup(xgboostjob, podTemplate)
+	setPodConfig(xgboostjob, podTemplate, data)
+	setPodData(xgboostjob, podTemplate, cluster)
+	setPodCheckpoint(xgboostjob, podTemplate)
+	setPodOutput(xgboostjob, podTemplate, cluster)
+	if err := setPodModel(xgboostjob, podTemplate); err != nil {
+		return err
+	}
+	return nil
 }
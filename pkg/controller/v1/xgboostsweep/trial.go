@@ -0,0 +1,204 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xgboostsweep
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	v1xgboost "github.com/kubeflow/xgboost-operator/pkg/apis/xgboostjob/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// labelXGBoostSweepName is the label of the sweep a trial belongs to.
+const labelXGBoostSweepName = "xgboostsweep-name"
+
+// computeTotalTrials returns the number of trials of the sweep: all the combinations of
+// the values of the parameters with the Grid algorithm, at most MaxTrials.
+func computeTotalTrials(spec *v1xgboost.XGBoostSweepSpec) int {
+	total := 0
+	if spec.Algorithm != v1xgboost.SweepAlgorithmRandom && len(spec.Parameters) > 0 {
+		total = 1
+		for _, param := range spec.Parameters {
+			total *= len(param.Values)
+		}
+	}
+	if spec.MaxTrials != nil && (spec.Algorithm == v1xgboost.SweepAlgorithmRandom || int(*spec.MaxTrials) < total) {
+		total = int(*spec.MaxTrials)
+	}
+	return total
+}
+
+// computeTrialParameters returns the values of the parameters of the trial of the given
+// index. With the Grid algorithm, the index is the combination of the values, the last
+// parameter changing the fastest. With the Random algorithm, the values are drawn from a
+// source seeded by the index, so that a trial gets the same values if it is created again.
+func computeTrialParameters(sweep *v1xgboost.XGBoostSweep, index int) (map[string]string, error) {
+	params := make(map[string]string, len(sweep.Spec.Parameters))
+	if sweep.Spec.Algorithm != v1xgboost.SweepAlgorithmRandom {
+		for i := len(sweep.Spec.Parameters) - 1; i >= 0; i-- {
+			param := sweep.Spec.Parameters[i]
+			params[param.Name] = param.Values[index%len(param.Values)]
+			index /= len(param.Values)
+		}
+		return params, nil
+	}
+
+	rng := rand.New(rand.NewSource(getSeed(sweep) + int64(index)))
+	for _, param := range sweep.Spec.Parameters {
+		if len(param.Values) > 0 {
+			params[param.Name] = param.Values[rng.Intn(len(param.Values))]
+			continue
+		}
+		minInt, minErr := strconv.ParseInt(param.Min, 10, 64)
+		maxInt, maxErr := strconv.ParseInt(param.Max, 10, 64)
+		// A range of more than MaxInt64 integers overflows, it is drawn as floats.
+		if span := maxInt - minInt; minErr == nil && maxErr == nil && span >= 0 && span < math.MaxInt64 {
+			params[param.Name] = strconv.FormatInt(minInt+rng.Int63n(maxInt-minInt+1), 10)
+			continue
+		}
+		min, err := strconv.ParseFloat(param.Min, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid min %s of parameter %s: %v", param.Min, param.Name, err)
+		}
+		max, err := strconv.ParseFloat(param.Max, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid max %s of parameter %s: %v", param.Max, param.Name, err)
+		}
+		params[param.Name] = strconv.FormatFloat(min+rng.Float64()*(max-min), 'g', 6, 64)
+	}
+	return params, nil
+}
+
+// getSeed returns the seed of the Random algorithm, derived from the UID of the sweep if
+// it has none.
+func getSeed(sweep *v1xgboost.XGBoostSweep) int64 {
+	if sweep.Spec.Seed != nil {
+		return *sweep.Spec.Seed
+	}
+	h := fnv.New64a()
+	h.Write([]byte(sweep.UID))
+	return int64(h.Sum64())
+}
+
+// newTrial returns the XGBoostJob of the trial of the given index, created from the
+// template of the sweep with the given parameters. Every trial saves its model to its own
+// directory of the output of the template.
+func newTrial(sweep *v1xgboost.XGBoostSweep, index int, params map[string]string) *v1xgboost.XGBoostJob {
+	template := sweep.Spec.Template.DeepCopy()
+	name := fmt.Sprintf("%s-%d", sweep.Name, index)
+
+	labels := template.Labels
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[labelXGBoostSweepName] = sweep.Name
+	job := &v1xgboost.XGBoostJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       sweep.Namespace,
+			Labels:          labels,
+			Annotations:     template.Annotations,
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(sweep, v1xgboost.SchemeGroupVersion.WithKind("XGBoostSweep"))},
+		},
+		Spec: template.Spec,
+	}
+
+	if job.Spec.XGBParams == nil {
+		job.Spec.XGBParams = &v1xgboost.XGBoostParams{}
+	}
+	if job.Spec.XGBParams.Extra == nil {
+		job.Spec.XGBParams.Extra = make(map[string]string, len(params))
+	}
+	for key, val := range params {
+		job.Spec.XGBParams.Extra[key] = val
+	}
+
+	if output := job.Spec.Output; output != nil {
+		if output.ClaimName != "" {
+			output.SubPath = path.Join(output.SubPath, name)
+		} else {
+			output.URI = getTrialOutputURI(output.URI, name)
+		}
+	}
+	return job
+}
+
+// getTrialOutputURI returns where the trial of the given name saves its model: under the
+// prefix uri, e.g. oss://models/iris/<trial>/, or next to the model file uri, e.g.
+// oss://models/iris/<trial>/model.json for oss://models/iris/model.json.
+func getTrialOutputURI(uri, name string) string {
+	u, err := url.Parse(uri)
+	if err != nil || strings.Trim(u.Path, "/") == "" || strings.HasSuffix(uri, "/") {
+		return strings.TrimSuffix(uri, "/") + "/" + name + "/"
+	}
+	i := strings.LastIndex(uri, "/")
+	return uri[:i+1] + name + uri[i:]
+}
+
+// getTrialMetric returns the metric of the given name reported by the trial with its
+// model, else in its last training metrics.
+func getTrialMetric(job *v1xgboost.XGBoostJob, name string) string {
+	if model := job.Status.Model; model != nil && model.Metrics[name] != "" {
+		return model.Metrics[name]
+	}
+	if training := job.Status.TrainingMetrics; training != nil {
+		return training.Metrics[name]
+	}
+	return ""
+}
+
+// countRunningTrials returns the number of trials that have not finished.
+func countRunningTrials(trials []v1xgboost.SweepTrial) int {
+	running := 0
+	for _, trial := range trials {
+		if trial.Phase == v1xgboost.TrialPhaseRunning {
+			running++
+		}
+	}
+	return running
+}
+
+// computeBestTrial returns the succeeded trial with the best metric, or nil if no trial
+// succeeded with the metric.
+func computeBestTrial(objective *v1xgboost.SweepObjective, trials []v1xgboost.SweepTrial) *v1xgboost.SweepTrial {
+	var best *v1xgboost.SweepTrial
+	var bestMetric float64
+	for i := range trials {
+		trial := &trials[i]
+		if trial.Phase != v1xgboost.TrialPhaseSucceeded {
+			continue
+		}
+		metric, err := strconv.ParseFloat(trial.Metric, 64)
+		if err != nil {
+			continue
+		}
+		better := metric > bestMetric
+		if objective.Goal == v1xgboost.ObjectiveGoalMinimize {
+			better = metric < bestMetric
+		}
+		if best == nil || better {
+			best, bestMetric = trial, metric
+		}
+	}
+	return best.DeepCopy()
+}
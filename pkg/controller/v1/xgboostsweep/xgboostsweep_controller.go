@@ -0,0 +1,299 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package xgboostsweep runs the trials of XGBoostSweeps as XGBoostJobs and reports the
+// best one.
+package xgboostsweep
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	commonv1 "github.com/kubeflow/common/pkg/apis/common/v1"
+	commonutil "github.com/kubeflow/common/pkg/util"
+	v1xgboost "github.com/kubeflow/xgboost-operator/pkg/apis/xgboostjob/v1"
+	"github.com/kubeflow/xgboost-operator/pkg/apis/xgboostjob/validation"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const controllerName = "xgboostsweep-operator"
+
+// Reasons for sweep events and conditions.
+const (
+	xgboostSweepCreatedReason   = "XGBoostSweepCreated"
+	xgboostSweepRunningReason   = "XGBoostSweepRunning"
+	xgboostSweepSucceededReason = "XGBoostSweepSucceeded"
+	xgboostSweepFailedReason    = "XGBoostSweepFailed"
+	// xgboostSweepInvalidReason is added in a sweep when its spec does not pass validation.
+	xgboostSweepInvalidReason = "XGBoostSweepInvalid"
+	// trialCreatedReason is the reason of the event of the creation of a trial.
+	trialCreatedReason = "TrialCreated"
+)
+
+// Add creates a new XGBoostSweep Controller and adds it to the Manager. The Manager will
+// set fields on the Controller and Start it when the Manager is Started.
+func Add(mgr manager.Manager) error {
+	return add(mgr, newReconciler(mgr))
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager) reconcile.Reconciler {
+	return &ReconcileXGBoostSweep{
+		Client:    mgr.GetClient(),
+		apiReader: mgr.GetAPIReader(),
+		scheme:    mgr.GetScheme(),
+		recorder:  mgr.GetEventRecorderFor(controllerName),
+	}
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	c, err := controller.New("xgboostsweep-controller", mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to XGBoostSweep
+	err = c.Watch(&source.Kind{Type: &v1xgboost.XGBoostSweep{}}, &handler.EnqueueRequestForObject{})
+	if err != nil {
+		return err
+	}
+
+	// Watch for the trials of the sweeps
+	err = c.Watch(&source.Kind{Type: &v1xgboost.XGBoostJob{}}, &handler.EnqueueRequestForOwner{
+		IsController: true,
+		OwnerType:    &v1xgboost.XGBoostSweep{},
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+var _ reconcile.Reconciler = &ReconcileXGBoostSweep{}
+
+// ReconcileXGBoostSweep reconciles a XGBoostSweep object
+type ReconcileXGBoostSweep struct {
+	client.Client
+	// apiReader reads the trials missing from the cache of the client from the API server.
+	apiReader client.Reader
+	scheme    *runtime.Scheme
+	recorder  record.EventRecorder
+}
+
+// Reconcile creates the trials of a sweep, at most MaxParallelTrials at a time, and reports
+// their progress and the best of them in the sweep status.
+// +kubebuilder:rbac:groups=xgboostjob.kubeflow.org,resources=xgboostsweeps,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=xgboostjob.kubeflow.org,resources=xgboostsweeps/status,verbs=get;update;patch
+func (r *ReconcileXGBoostSweep) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	sweep := &v1xgboost.XGBoostSweep{}
+	err := r.Get(context.Background(), request.NamespacedName, sweep)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// The trials are garbage collected with the sweep.
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+	if sweep.DeletionTimestamp != nil || isFinished(&sweep.Status) {
+		return reconcile.Result{}, nil
+	}
+	// Set defaults for the sweep in memory, there is no defaulting webhook for sweeps.
+	r.scheme.Default(sweep)
+
+	modified := sweep.DeepCopy()
+	if errs := validation.ValidateV1XGBoostSweep(sweep); len(errs) > 0 {
+		msg := fmt.Sprintf("XGBoostSweep %s is failed because it is invalid: %v", sweep.Name, errs.ToAggregate())
+		r.recorder.Event(sweep, corev1.EventTypeWarning, xgboostSweepInvalidReason, msg)
+		finish(&modified.Status, commonv1.JobFailed, xgboostSweepInvalidReason, msg)
+		return reconcile.Result{}, r.patchStatus(sweep, modified)
+	}
+
+	if len(sweep.Status.Conditions) == 0 {
+		updateConditions(&modified.Status, commonv1.JobCreated, xgboostSweepCreatedReason,
+			fmt.Sprintf("XGBoostSweep %s is created.", sweep.Name))
+	}
+	if err := r.syncTrials(sweep, &modified.Status); err != nil {
+		logrus.Warnf("Sync trials of XGBoost Sweep %s error %v", sweep.Name, err)
+		return reconcile.Result{}, err
+	}
+	createErr := r.createTrials(sweep, &modified.Status)
+	modified.Status.BestTrial = computeBestTrial(&sweep.Spec.Objective, modified.Status.Trials)
+
+	total := computeTotalTrials(&sweep.Spec)
+	if createErr == nil && len(modified.Status.Trials) == total && countRunningTrials(modified.Status.Trials) == 0 {
+		if best := modified.Status.BestTrial; best != nil {
+			msg := fmt.Sprintf("XGBoostSweep %s successfully completed, the best of its %d trials is %s with %s %s.",
+				sweep.Name, total, best.Name, sweep.Spec.Objective.MetricName, best.Metric)
+			r.recorder.Event(sweep, corev1.EventTypeNormal, xgboostSweepSucceededReason, msg)
+			finish(&modified.Status, commonv1.JobSucceeded, xgboostSweepSucceededReason, msg)
+		} else {
+			msg := fmt.Sprintf("XGBoostSweep %s is failed because none of its %d trials succeeded with the metric %s.",
+				sweep.Name, total, sweep.Spec.Objective.MetricName)
+			r.recorder.Event(sweep, corev1.EventTypeNormal, xgboostSweepFailedReason, msg)
+			finish(&modified.Status, commonv1.JobFailed, xgboostSweepFailedReason, msg)
+		}
+	} else if len(modified.Status.Trials) > 0 && !hasCondition(&modified.Status, commonv1.JobRunning) {
+		updateConditions(&modified.Status, commonv1.JobRunning, xgboostSweepRunningReason,
+			fmt.Sprintf("XGBoostSweep %s is running.", sweep.Name))
+	}
+
+	if err := r.patchStatus(sweep, modified); err != nil {
+		return reconcile.Result{}, err
+	}
+	return reconcile.Result{}, createErr
+}
+
+// syncTrials updates the phase and the metric of the running trials of the sweep.
+func (r *ReconcileXGBoostSweep) syncTrials(sweep *v1xgboost.XGBoostSweep, status *v1xgboost.XGBoostSweepStatus) error {
+	for i := range status.Trials {
+		trial := &status.Trials[i]
+		if trial.Phase != v1xgboost.TrialPhaseRunning {
+			continue
+		}
+		job, err := r.getTrial(sweep, trial.Name)
+		if errors.IsNotFound(err) {
+			trial.Phase = v1xgboost.TrialPhaseFailed
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		switch {
+		case commonutil.IsSucceeded(job.Status.JobStatus):
+			trial.Phase = v1xgboost.TrialPhaseSucceeded
+			trial.Metric = getTrialMetric(job, sweep.Spec.Objective.MetricName)
+		case commonutil.IsFailed(job.Status.JobStatus):
+			trial.Phase = v1xgboost.TrialPhaseFailed
+		}
+	}
+	return nil
+}
+
+// getTrial returns the trial of the sweep with the given name. A trial missing from the
+// cache, e.g. just created, is read from the API server. A job of the same name not
+// controlled by the sweep is not found.
+func (r *ReconcileXGBoostSweep) getTrial(sweep *v1xgboost.XGBoostSweep, name string) (*v1xgboost.XGBoostJob, error) {
+	job := &v1xgboost.XGBoostJob{}
+	key := types.NamespacedName{Namespace: sweep.Namespace, Name: name}
+	err := r.Get(context.Background(), key, job)
+	if errors.IsNotFound(err) && r.apiReader != nil {
+		err = r.apiReader.Get(context.Background(), key, job)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if ref := metav1.GetControllerOf(job); ref == nil || ref.UID != sweep.UID {
+		return nil, errors.NewNotFound(v1xgboost.Resource("xgboostjobs"), name)
+	}
+	return job, nil
+}
+
+// createTrials creates the next trials of the sweep until MaxParallelTrials are running,
+// and records them in status.
+func (r *ReconcileXGBoostSweep) createTrials(sweep *v1xgboost.XGBoostSweep, status *v1xgboost.XGBoostSweepStatus) error {
+	total := computeTotalTrials(&sweep.Spec)
+	for len(status.Trials) < total && countRunningTrials(status.Trials) < int(*sweep.Spec.MaxParallelTrials) {
+		index := len(status.Trials)
+		params, err := computeTrialParameters(sweep, index)
+		if err != nil {
+			return err
+		}
+		job := newTrial(sweep, index, params)
+		// A trial already exists if the status could not be updated after its creation.
+		if err := r.Create(context.Background(), job); err != nil && !errors.IsAlreadyExists(err) {
+			return err
+		}
+		r.recorder.Eventf(sweep, corev1.EventTypeNormal, trialCreatedReason, "Created trial %s with %v", job.Name, params)
+		if status.StartTime == nil {
+			now := metav1.Now()
+			status.StartTime = &now
+		}
+		status.Trials = append(status.Trials, v1xgboost.SweepTrial{
+			Name:       job.Name,
+			Parameters: params,
+			Phase:      v1xgboost.TrialPhaseRunning,
+		})
+	}
+	return nil
+}
+
+// patchStatus patches the status of the original sweep to the status of modified, if it
+// changed. The patch carries the resourceVersion of the original sweep, so it is rejected
+// with a conflict if the sweep has been changed since.
+func (r *ReconcileXGBoostSweep) patchStatus(original, modified *v1xgboost.XGBoostSweep) error {
+	if apiequality.Semantic.DeepEqual(original.Status, modified.Status) {
+		return nil
+	}
+	data, err := client.MergeFrom(original).Data(modified)
+	if err != nil {
+		return err
+	}
+	patch := map[string]interface{}{}
+	if err := json.Unmarshal(data, &patch); err != nil {
+		return err
+	}
+	patch["metadata"] = map[string]interface{}{"resourceVersion": original.ResourceVersion}
+	if data, err = json.Marshal(patch); err != nil {
+		return err
+	}
+
+	return r.Status().Patch(context.Background(), modified, client.ConstantPatch(types.MergePatchType, data))
+}
+
+// updateConditions sets the condition of the given type in the sweep status.
+func updateConditions(status *v1xgboost.XGBoostSweepStatus, conditionType commonv1.JobConditionType, reason, msg string) {
+	jobStatus := &commonv1.JobStatus{Conditions: status.Conditions}
+	// UpdateJobConditions never fails.
+	_ = commonutil.UpdateJobConditions(jobStatus, conditionType, reason, msg)
+	status.Conditions = jobStatus.Conditions
+}
+
+// finish marks the sweep succeeded or failed.
+func finish(status *v1xgboost.XGBoostSweepStatus, conditionType commonv1.JobConditionType, reason, msg string) {
+	updateConditions(status, conditionType, reason, msg)
+	now := metav1.Now()
+	status.CompletionTime = &now
+}
+
+// hasCondition returns true if the sweep has a true condition of the given type.
+func hasCondition(status *v1xgboost.XGBoostSweepStatus, conditionType commonv1.JobConditionType) bool {
+	for _, condition := range status.Conditions {
+		if condition.Type == conditionType && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// isFinished returns true if the sweep succeeded or failed.
+func isFinished(status *v1xgboost.XGBoostSweepStatus) bool {
+	return hasCondition(status, commonv1.JobSucceeded) || hasCondition(status, commonv1.JobFailed)
+}
@@ -0,0 +1,257 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xgboostsweep
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"strconv"
+	"testing"
+
+	commonv1 "github.com/kubeflow/common/pkg/apis/common/v1"
+	commonutil "github.com/kubeflow/common/pkg/util"
+	v1xgboost "github.com/kubeflow/xgboost-operator/pkg/apis/xgboostjob/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func newSweep(algorithm v1xgboost.SweepAlgorithm, params ...v1xgboost.SweepParameter) *v1xgboost.XGBoostSweep {
+	replicas := int32(1)
+	replicaSpec := &commonv1.ReplicaSpec{
+		Replicas: &replicas,
+		Template: corev1.PodTemplateSpec{
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: v1xgboost.DefaultContainerName, Image: "test-image-for-kubeflow-xgboost-operator:latest"}},
+			},
+		},
+	}
+	return &v1xgboost.XGBoostSweep{
+		ObjectMeta: metav1.ObjectMeta{Name: "iris", Namespace: metav1.NamespaceDefault, UID: "6d0b7b3a"},
+		Spec: v1xgboost.XGBoostSweepSpec{
+			Template: v1xgboost.XGBoostJobTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"team": "ranking"}},
+				Spec: v1xgboost.XGBoostJobSpec{
+					XGBReplicaSpecs: map[commonv1.ReplicaType]*commonv1.ReplicaSpec{
+						commonv1.ReplicaType(v1xgboost.XGBoostReplicaTypeMaster): replicaSpec,
+						commonv1.ReplicaType(v1xgboost.XGBoostReplicaTypeWorker): replicaSpec.DeepCopy(),
+					},
+					Output: &v1xgboost.OutputSpec{URI: "oss://models/iris/model.json"},
+				},
+			},
+			Algorithm:  algorithm,
+			Parameters: params,
+			Objective:  v1xgboost.SweepObjective{MetricName: "validation-logloss", Goal: v1xgboost.ObjectiveGoalMinimize},
+		},
+	}
+}
+
+func TestComputeTrialParameters(t *testing.T) {
+	grid := newSweep(v1xgboost.SweepAlgorithmGrid,
+		v1xgboost.SweepParameter{Name: "max_depth", Values: []string{"3", "6"}},
+		v1xgboost.SweepParameter{Name: "eta", Values: []string{"0.1", "0.2", "0.3"}})
+	if total := computeTotalTrials(&grid.Spec); total != 6 {
+		t.Errorf("Got %d grid trials. Expected 6", total)
+	}
+	for i, expected := range []map[string]string{
+		{"max_depth": "3", "eta": "0.1"},
+		{"max_depth": "3", "eta": "0.2"},
+		{"max_depth": "3", "eta": "0.3"},
+		{"max_depth": "6", "eta": "0.1"},
+	} {
+		params, err := computeTrialParameters(grid, i)
+		if err != nil || !reflect.DeepEqual(params, expected) {
+			t.Errorf("Case %d: Got %v, %v. Expected %v", i, params, err, expected)
+		}
+	}
+
+	maxTrials := int32(20)
+	random := newSweep(v1xgboost.SweepAlgorithmRandom,
+		v1xgboost.SweepParameter{Name: "max_depth", Min: "3", Max: "8"},
+		v1xgboost.SweepParameter{Name: "eta", Min: "0.01", Max: "0.3"},
+		v1xgboost.SweepParameter{Name: "tree_method", Values: []string{"hist", "approx"}})
+	random.Spec.MaxTrials = &maxTrials
+	if total := computeTotalTrials(&random.Spec); total != 20 {
+		t.Errorf("Got %d random trials. Expected 20", total)
+	}
+	for i := 0; i < 20; i++ {
+		params, err := computeTrialParameters(random, i)
+		if err != nil {
+			t.Fatalf("Case %d: failed to compute the parameters: %v", i, err)
+		}
+		again, _ := computeTrialParameters(random, i)
+		if !reflect.DeepEqual(params, again) {
+			t.Errorf("Case %d: Got %v then %v. Expected the same parameters", i, params, again)
+		}
+		depth, err := strconv.Atoi(params["max_depth"])
+		if err != nil || depth < 3 || depth > 8 {
+			t.Errorf("Case %d: Got max_depth %s. Expected an integer in [3, 8]", i, params["max_depth"])
+		}
+		eta, err := strconv.ParseFloat(params["eta"], 64)
+		if err != nil || eta < 0.01 || eta > 0.3 {
+			t.Errorf("Case %d: Got eta %s. Expected a number in [0.01, 0.3]", i, params["eta"])
+		}
+		if method := params["tree_method"]; method != "hist" && method != "approx" {
+			t.Errorf("Case %d: Got tree_method %s", i, method)
+		}
+	}
+	wide := newSweep(v1xgboost.SweepAlgorithmRandom,
+		v1xgboost.SweepParameter{Name: "seed", Min: strconv.FormatInt(math.MinInt64, 10), Max: strconv.FormatInt(math.MaxInt64, 10)})
+	for i := 0; i < 20; i++ {
+		params, err := computeTrialParameters(wide, i)
+		if err != nil {
+			t.Fatalf("Case %d: failed to compute the parameters of a range wider than int64: %v", i, err)
+		}
+		if _, err := strconv.ParseFloat(params["seed"], 64); err != nil {
+			t.Errorf("Case %d: Got seed %s. Expected a number", i, params["seed"])
+		}
+	}
+}
+
+func TestNewTrial(t *testing.T) {
+	type tc struct {
+		output   v1xgboost.OutputSpec
+		expected v1xgboost.OutputSpec
+	}
+	testCase := []tc{
+		tc{output: v1xgboost.OutputSpec{URI: "oss://models/iris/model.json"}, expected: v1xgboost.OutputSpec{URI: "oss://models/iris/iris-1/model.json"}},
+		tc{output: v1xgboost.OutputSpec{URI: "oss://models/iris/"}, expected: v1xgboost.OutputSpec{URI: "oss://models/iris/iris-1/"}},
+		tc{output: v1xgboost.OutputSpec{URI: "oss://models"}, expected: v1xgboost.OutputSpec{URI: "oss://models/iris-1/"}},
+		tc{output: v1xgboost.OutputSpec{ClaimName: "models"}, expected: v1xgboost.OutputSpec{ClaimName: "models", SubPath: "iris-1"}},
+		tc{output: v1xgboost.OutputSpec{ClaimName: "models", SubPath: "sweeps"}, expected: v1xgboost.OutputSpec{ClaimName: "models", SubPath: "sweeps/iris-1"}},
+	}
+	for i, c := range testCase {
+		sweep := newSweep(v1xgboost.SweepAlgorithmGrid)
+		sweep.Spec.Template.Spec.Output = c.output.DeepCopy()
+		trial := newTrial(sweep, 1, map[string]string{"max_depth": "6"})
+
+		if trial.Name != "iris-1" || trial.Labels[labelXGBoostSweepName] != "iris" || trial.Labels["team"] != "ranking" {
+			t.Errorf("Case %d: Got trial %s with labels %v", i, trial.Name, trial.Labels)
+		}
+		if ref := metav1.GetControllerOf(trial); ref == nil || ref.UID != sweep.UID || ref.Kind != "XGBoostSweep" {
+			t.Errorf("Case %d: Got controller %v. Expected the sweep", i, ref)
+		}
+		if trial.Spec.XGBParams.Extra["max_depth"] != "6" {
+			t.Errorf("Case %d: Got parameters %v", i, trial.Spec.XGBParams)
+		}
+		if *trial.Spec.Output != c.expected {
+			t.Errorf("Case %d: Got output %+v. Expected %+v", i, *trial.Spec.Output, c.expected)
+		}
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	s := runtime.NewScheme()
+	if err := scheme.AddToScheme(s); err != nil {
+		t.Fatalf("Failed to build the scheme: %v", err)
+	}
+	if err := v1xgboost.AddToScheme(s); err != nil {
+		t.Fatalf("Failed to build the scheme: %v", err)
+	}
+
+	maxParallelTrials := int32(2)
+	sweep := newSweep(v1xgboost.SweepAlgorithmGrid,
+		v1xgboost.SweepParameter{Name: "max_depth", Values: []string{"3", "6"}},
+		v1xgboost.SweepParameter{Name: "eta", Values: []string{"0.1", "0.3"}})
+	sweep.Spec.MaxParallelTrials = &maxParallelTrials
+	r := &ReconcileXGBoostSweep{
+		Client:   fake.NewFakeClientWithScheme(s, sweep),
+		scheme:   s,
+		recorder: record.NewFakeRecorder(100),
+	}
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: sweep.Namespace, Name: sweep.Name}}
+	reconcileSweep := func() *v1xgboost.XGBoostSweep {
+		if _, err := r.Reconcile(request); err != nil {
+			t.Fatalf("Failed to reconcile: %v", err)
+		}
+		latest := &v1xgboost.XGBoostSweep{}
+		if err := r.Get(context.Background(), request.NamespacedName, latest); err != nil {
+			t.Fatalf("Failed to get the sweep: %v", err)
+		}
+		return latest
+	}
+	finishTrial := func(name string, condition commonv1.JobConditionType, logloss string) {
+		job := &v1xgboost.XGBoostJob{}
+		if err := r.Get(context.Background(), types.NamespacedName{Namespace: sweep.Namespace, Name: name}, job); err != nil {
+			t.Fatalf("Failed to get trial %s: %v", name, err)
+		}
+		if err := commonutil.UpdateJobConditions(&job.Status.JobStatus, condition, "", ""); err != nil {
+			t.Fatalf("Failed to update the conditions: %v", err)
+		}
+		if logloss != "" {
+			job.Status.Model = &v1xgboost.ModelStatus{URI: job.Spec.Output.URI, Metrics: map[string]string{"validation-logloss": logloss}}
+		}
+		if err := r.Update(context.Background(), job); err != nil {
+			t.Fatalf("Failed to update trial %s: %v", name, err)
+		}
+	}
+
+	// At most 2 trials run at the same time.
+	latest := reconcileSweep()
+	if len(latest.Status.Trials) != 2 || !hasCondition(&latest.Status, commonv1.JobRunning) || latest.Status.StartTime == nil {
+		t.Fatalf("Got status %+v. Expected 2 running trials", latest.Status)
+	}
+	finishTrial("iris-0", commonv1.JobSucceeded, "0.42")
+	finishTrial("iris-1", commonv1.JobFailed, "")
+
+	latest = reconcileSweep()
+	phases := []v1xgboost.TrialPhase{}
+	for _, trial := range latest.Status.Trials {
+		phases = append(phases, trial.Phase)
+	}
+	expectedPhases := []v1xgboost.TrialPhase{v1xgboost.TrialPhaseSucceeded, v1xgboost.TrialPhaseFailed, v1xgboost.TrialPhaseRunning, v1xgboost.TrialPhaseRunning}
+	if !reflect.DeepEqual(phases, expectedPhases) {
+		t.Fatalf("Got trial phases %v. Expected %v", phases, expectedPhases)
+	}
+	if best := latest.Status.BestTrial; best == nil || best.Name != "iris-0" {
+		t.Errorf("Got best trial %v. Expected iris-0", best)
+	}
+	finishTrial("iris-2", commonv1.JobSucceeded, "0.35")
+	finishTrial("iris-3", commonv1.JobSucceeded, "")
+	// A trial without model metrics is ranked by its last training metrics.
+	trial := &v1xgboost.XGBoostJob{}
+	if err := r.Get(context.Background(), types.NamespacedName{Namespace: sweep.Namespace, Name: "iris-3"}, trial); err != nil {
+		t.Fatalf("Failed to get trial iris-3: %v", err)
+	}
+	trial.Status.TrainingMetrics = &v1xgboost.TrainingMetricsStatus{Iteration: 99, Metrics: map[string]string{"validation-logloss": "0.51"}}
+	if err := r.Update(context.Background(), trial); err != nil {
+		t.Fatalf("Failed to update trial iris-3: %v", err)
+	}
+
+	// The sweep succeeds with the trial of the lowest logloss.
+	latest = reconcileSweep()
+	if !hasCondition(&latest.Status, commonv1.JobSucceeded) || latest.Status.CompletionTime == nil {
+		t.Errorf("Got conditions %v. Expected the sweep to succeed", latest.Status.Conditions)
+	}
+	expectedBest := v1xgboost.SweepTrial{
+		Name:       "iris-2",
+		Parameters: map[string]string{"max_depth": "6", "eta": "0.1"},
+		Phase:      v1xgboost.TrialPhaseSucceeded,
+		Metric:     "0.35",
+	}
+	if best := latest.Status.BestTrial; best == nil || !reflect.DeepEqual(*best, expectedBest) {
+		t.Errorf("Got best trial %+v. Expected %+v", best, expectedBest)
+	}
+	if metric := latest.Status.Trials[3].Metric; metric != "0.51" {
+		t.Errorf("Got metric %s of trial iris-3. Expected 0.51", metric)
+	}
+}
@@ -0,0 +1,128 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracker
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ParseEvalLog parses the evaluation line printed after a boosting round, either by
+// XGBoost, e.g. "[42]\teval-auc:0.91\ttrain-auc:0.95", or by LightGBM, e.g.
+// "[LightGBM] [Info] Iteration:42, valid_1 auc : 0.91". LightGBM prints a line per metric,
+// named like the XGBoost ones, e.g. valid_1-auc. It returns false if the line is not an
+// evaluation line.
+func ParseEvalLog(line string) (int, map[string]float64, bool) {
+	line = strings.TrimSpace(line)
+	if strings.HasPrefix(line, lightGBMLogPrefix) {
+		return parseLightGBMEvalLog(line)
+	}
+	if !strings.HasPrefix(line, "[") {
+		return 0, nil, false
+	}
+	end := strings.Index(line, "]")
+	if end < 0 {
+		return 0, nil, false
+	}
+	iteration, err := strconv.Atoi(line[1:end])
+	if err != nil {
+		return 0, nil, false
+	}
+
+	metrics := map[string]float64{}
+	for _, field := range strings.Split(line[end+1:], "\t") {
+		field = strings.TrimSpace(field)
+		sep := strings.LastIndex(field, ":")
+		if sep < 0 {
+			continue
+		}
+		name := strings.TrimSpace(field[:sep])
+		value := strings.TrimSpace(field[sep+1:])
+		v, err := strconv.ParseFloat(value, 64)
+		if plus := strings.LastIndex(value, "+"); err != nil && plus > 0 {
+			// xgboost.cv prints the standard deviation after the mean, e.g. 0.91+0.01.
+			v, err = strconv.ParseFloat(value[:plus], 64)
+		}
+		if name == "" || err != nil {
+			continue
+		}
+		metrics[name] = v
+	}
+	if len(metrics) == 0 {
+		return 0, nil, false
+	}
+	return iteration, metrics, true
+}
+
+const (
+	// lightGBMLogPrefix starts every line logged by LightGBM.
+	lightGBMLogPrefix = "[LightGBM]"
+	// lightGBMIterationPrefix starts the evaluation lines of LightGBM, after the log level.
+	lightGBMIterationPrefix = "Iteration:"
+)
+
+// parseLightGBMEvalLog parses the evaluation line of a metric printed by LightGBM, e.g.
+// "[LightGBM] [Info] Iteration:42, valid_1 auc : 0.91".
+func parseLightGBMEvalLog(line string) (int, map[string]float64, bool) {
+	line = strings.TrimSpace(strings.TrimPrefix(line, lightGBMLogPrefix))
+	// Skip the log level, e.g. [Info].
+	if end := strings.Index(line, "]"); strings.HasPrefix(line, "[") && end > 0 {
+		line = strings.TrimSpace(line[end+1:])
+	}
+	if !strings.HasPrefix(line, lightGBMIterationPrefix) {
+		return 0, nil, false
+	}
+	line = line[len(lightGBMIterationPrefix):]
+	comma := strings.Index(line, ",")
+	if comma < 0 {
+		return 0, nil, false
+	}
+	iteration, err := strconv.Atoi(strings.TrimSpace(line[:comma]))
+	if err != nil {
+		return 0, nil, false
+	}
+
+	field := line[comma+1:]
+	sep := strings.LastIndex(field, ":")
+	if sep < 0 {
+		return 0, nil, false
+	}
+	name := strings.Join(strings.Fields(field[:sep]), "-")
+	v, err := strconv.ParseFloat(strings.TrimSpace(field[sep+1:]), 64)
+	if name == "" || err != nil {
+		return 0, nil, false
+	}
+	return iteration, map[string]float64{name: v}, true
+}
+
+// ReportEvalLog copies the lines read from r to w, and calls onEval with the metrics of
+// the evaluation lines among them, until r is exhausted.
+func ReportEvalLog(r io.Reader, w io.Writer, onEval func(iteration int, metrics map[string]float64)) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+		if iteration, metrics, ok := ParseEvalLog(line); ok {
+			onEval(iteration, metrics)
+		}
+	}
+	return scanner.Err()
+}
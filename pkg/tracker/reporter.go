@@ -0,0 +1,114 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracker
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	v1xgboost "github.com/kubeflow/xgboost-operator/pkg/apis/xgboostjob/v1"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// trainingMetrics is the JSON of the training metrics annotation.
+type trainingMetrics struct {
+	Iteration int                `json:"iteration"`
+	Metrics   map[string]float64 `json:"metrics"`
+}
+
+// MetricsReporter annotates the pod of the tracker with the last evaluation metrics
+// printed by the workers, so that the controller reports them in the job status. The pod
+// is patched at most once per interval with the last metrics received.
+type MetricsReporter struct {
+	client    kubernetes.Interface
+	namespace string
+	name      string
+	interval  time.Duration
+
+	mu sync.Mutex
+	// last are the metrics of the last iteration reported.
+	last trainingMetrics
+	// pending is true if last has not been patched yet.
+	pending bool
+}
+
+// NewMetricsReporter returns a reporter annotating the pod name of namespace.
+func NewMetricsReporter(client kubernetes.Interface, namespace, name string, interval time.Duration) *MetricsReporter {
+	return &MetricsReporter{client: client, namespace: namespace, name: name, interval: interval}
+}
+
+// Report records the metrics of an iteration, to be patched on the pod. The metrics
+// reported for the same iteration, e.g. by a LightGBM line per metric, are merged.
+func (r *MetricsReporter) Report(iteration int, metrics map[string]float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.last.Metrics == nil || r.last.Iteration != iteration {
+		r.last = trainingMetrics{Iteration: iteration, Metrics: map[string]float64{}}
+	}
+	for name, value := range metrics {
+		r.last.Metrics[name] = value
+	}
+	r.pending = true
+}
+
+// Run patches the pod every interval until stop is closed, then patches it a last time.
+func (r *MetricsReporter) Run(stop <-chan struct{}) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			r.flush()
+		case <-stop:
+			r.flush()
+			return
+		}
+	}
+}
+
+// flush patches the pending metrics on the pod. They are kept pending if the patch fails.
+func (r *MetricsReporter) flush() {
+	r.mu.Lock()
+	if !r.pending {
+		r.mu.Unlock()
+		return
+	}
+	data, err := json.Marshal(r.last)
+	r.pending = false
+	r.mu.Unlock()
+	if err != nil {
+		logrus.Warnf("failed to encode the training metrics: %v", err)
+		return
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{v1xgboost.TrainingMetricsAnnotation: string(data)},
+		},
+	})
+	if err == nil {
+		_, err = r.client.CoreV1().Pods(r.namespace).Patch(r.name, types.MergePatchType, patch)
+	}
+	if err != nil {
+		logrus.Warnf("failed to annotate pod %s/%s with the training metrics: %v", r.namespace, r.name, err)
+		r.mu.Lock()
+		r.pending = true
+		r.mu.Unlock()
+	}
+}
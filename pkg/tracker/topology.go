@@ -0,0 +1,121 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracker
+
+// linkMap is the topology of the workers, keyed by rank: a binary tree used by
+// allreduce and broadcast, and a ring used to recover the data of a failed worker.
+type linkMap struct {
+	tree   map[int][]int
+	parent map[int]int
+	ring   map[int][2]int
+}
+
+// getNeighbors returns the ranks linked to rank in a binary tree of n workers.
+func getNeighbors(rank, n int) []int {
+	rank++
+	neighbors := []int{}
+	if rank > 1 {
+		neighbors = append(neighbors, rank/2-1)
+	}
+	if rank*2-1 < n {
+		neighbors = append(neighbors, rank*2-1)
+	}
+	if rank*2 < n {
+		neighbors = append(neighbors, rank*2)
+	}
+	return neighbors
+}
+
+func getTree(n int) (map[int][]int, map[int]int) {
+	tree := make(map[int][]int, n)
+	parent := make(map[int]int, n)
+	for r := 0; r < n; r++ {
+		tree[r] = getNeighbors(r, n)
+		parent[r] = (r+1)/2 - 1
+	}
+	return tree, parent
+}
+
+// findShareRing returns a ring starting from r that tends to share links with the tree.
+func findShareRing(tree map[int][]int, parent map[int]int, r int) []int {
+	children := []int{}
+	for _, v := range tree[r] {
+		if v != parent[r] {
+			children = append(children, v)
+		}
+	}
+
+	ring := []int{r}
+	for i, v := range children {
+		sub := findShareRing(tree, parent, v)
+		if i == len(children)-1 {
+			for a, b := 0, len(sub)-1; a < b; a, b = a+1, b-1 {
+				sub[a], sub[b] = sub[b], sub[a]
+			}
+		}
+		ring = append(ring, sub...)
+	}
+	return ring
+}
+
+// getRing returns the previous and next rank of every rank in the ring.
+func getRing(tree map[int][]int, parent map[int]int) map[int][2]int {
+	order := findShareRing(tree, parent, 0)
+	n := len(order)
+	ring := make(map[int][2]int, n)
+	for i := range order {
+		ring[order[i]] = [2]int{order[(i+n-1)%n], order[(i+1)%n]}
+	}
+	return ring
+}
+
+// getLinkMap returns the topology of n workers. The ranks are renumbered along the
+// ring, so that rank r+1 is the next of rank r in the ring.
+func getLinkMap(n int) *linkMap {
+	tree, parent := getTree(n)
+	ring := getRing(tree, parent)
+
+	rmap := map[int]int{0: 0}
+	k := 0
+	for i := 0; i < n-1; i++ {
+		k = ring[k][1]
+		rmap[k] = i + 1
+	}
+
+	lm := &linkMap{
+		tree:   make(map[int][]int, n),
+		parent: make(map[int]int, n),
+		ring:   make(map[int][2]int, n),
+	}
+	for k, v := range ring {
+		lm.ring[rmap[k]] = [2]int{rmap[v[0]], rmap[v[1]]}
+	}
+	for k, v := range tree {
+		neighbors := make([]int, 0, len(v))
+		for _, x := range v {
+			neighbors = append(neighbors, rmap[x])
+		}
+		lm.tree[rmap[k]] = neighbors
+	}
+	for k, v := range parent {
+		if k == 0 {
+			lm.parent[rmap[k]] = -1
+		} else {
+			lm.parent[rmap[k]] = rmap[v]
+		}
+	}
+	return lm
+}
@@ -0,0 +1,217 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tracker implements the DMLC Rabit tracker. The tracker assigns a rank to
+// every XGBoost worker, tells each worker the address of the workers it links to in
+// the tree and ring topology, prints the messages sent by the workers, among which the
+// evaluation metrics of every boosting round, and exits once every worker has shut down.
+// It replaces the tracker.py that used to be started by the Master replica.
+package tracker
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Tracker is a Rabit tracker for a fixed number of workers.
+type Tracker struct {
+	listener   net.Listener
+	numWorkers int
+
+	// OnEval, if set, is called with the evaluation metrics printed by the workers after
+	// every boosting round.
+	OnEval func(iteration int, metrics map[string]float64)
+}
+
+// New returns a tracker for numWorkers workers listening on addr. The number of workers
+// announced by the first worker takes precedence over numWorkers.
+func New(addr string, numWorkers int) (*Tracker, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	logrus.Infof("start listen on %s", listener.Addr())
+	return &Tracker{listener: listener, numWorkers: numWorkers}, nil
+}
+
+// Addr returns the address the tracker listens on.
+func (t *Tracker) Addr() net.Addr {
+	return t.listener.Addr()
+}
+
+// Close stops listening.
+func (t *Tracker) Close() error {
+	return t.listener.Close()
+}
+
+// Run accepts the workers until all of them have shut down.
+func (t *Tracker) Run() error {
+	defer t.Close()
+
+	numWorkers := t.numWorkers
+	// shutdown are the ranks that finished the job.
+	shutdown := map[int]bool{}
+	// waitConn are the workers waiting for other workers to link to them.
+	waitConn := map[int]*worker{}
+	// jobMap maps a job id to its rank.
+	jobMap := map[string]int{}
+	// pending are the workers waiting to be assigned a rank.
+	var pending []*worker
+	// todo are the ranks not assigned yet.
+	var todo []int
+	var lm *linkMap
+	var startTime time.Time
+
+	for len(shutdown) != numWorkers || lm == nil {
+		conn, err := t.listener.Accept()
+		if err != nil {
+			return err
+		}
+		w, err := newWorker(conn)
+		if err != nil {
+			logrus.Warnf("drop connection from %s: %v", conn.RemoteAddr(), err)
+			conn.Close()
+			continue
+		}
+
+		switch w.cmd {
+		case cmdPrint:
+			msg, err := w.recvStr()
+			if err != nil {
+				logrus.Warnf("failed to receive message from %s: %v", w.host, err)
+			} else {
+				logrus.Info(strings.TrimSpace(msg))
+				if t.OnEval != nil {
+					if iteration, metrics, ok := ParseEvalLog(msg); ok {
+						t.OnEval(iteration, metrics)
+					}
+				}
+			}
+			conn.Close()
+			continue
+		case cmdShutdown:
+			conn.Close()
+			if w.rank < 0 || shutdown[w.rank] {
+				return fmt.Errorf("invalid shutdown from rank %d", w.rank)
+			}
+			if _, ok := waitConn[w.rank]; ok {
+				return fmt.Errorf("rank %d shut down while other workers wait to link to it", w.rank)
+			}
+			shutdown[w.rank] = true
+			logrus.Debugf("receive %s signal from %d", w.cmd, w.rank)
+			continue
+		case cmdStart, cmdRecover:
+		default:
+			conn.Close()
+			return fmt.Errorf("unknown command %q from %s", w.cmd, w.host)
+		}
+
+		// Build the topology once the first worker starts.
+		if lm == nil {
+			if w.cmd != cmdStart {
+				conn.Close()
+				return fmt.Errorf("worker %s recovers before any worker started", w.host)
+			}
+			if w.worldSize > 0 {
+				numWorkers = w.worldSize
+			}
+			lm = getLinkMap(numWorkers)
+			for r := 0; r < numWorkers; r++ {
+				todo = append(todo, r)
+			}
+		} else if w.worldSize != -1 && w.worldSize != numWorkers {
+			conn.Close()
+			return fmt.Errorf("worker %s announces world size %d, expected %d", w.host, w.worldSize, numWorkers)
+		}
+		if w.cmd == cmdRecover && w.rank < 0 {
+			conn.Close()
+			return fmt.Errorf("worker %s recovers without a rank", w.host)
+		}
+
+		rank := w.decideRank(jobMap)
+		if rank >= numWorkers {
+			conn.Close()
+			return fmt.Errorf("worker %s asks for rank %d of %d workers", w.host, rank, numWorkers)
+		}
+		if rank == -1 {
+			// Assign the ranks in batch, ordered by host, once all unranked workers are here.
+			if len(todo) == 0 {
+				conn.Close()
+				return fmt.Errorf("no rank left for worker %s", w.host)
+			}
+			pending = append(pending, w)
+			if len(pending) == len(todo) {
+				sort.SliceStable(pending, func(i, j int) bool { return pending[i].host < pending[j].host })
+				for _, p := range pending {
+					rank, todo = todo[0], todo[1:]
+					if p.jobID != "NULL" {
+						jobMap[p.jobID] = rank
+					}
+					if err := t.assign(p, rank, waitConn, lm); err != nil {
+						return err
+					}
+					logrus.Debugf("receive %s signal from %s, assign rank %d", p.cmd, p.host, rank)
+				}
+				pending = nil
+			}
+		} else {
+			todo = remove(todo, rank)
+			if err := t.assign(w, rank, waitConn, lm); err != nil {
+				return err
+			}
+			logrus.Debugf("receive %s signal from %d", w.cmd, rank)
+		}
+		if len(todo) == 0 && startTime.IsZero() {
+			logrus.Infof("@tracker All of %d nodes getting started", numWorkers)
+			startTime = time.Now()
+		}
+		logrus.Infof("worker(ip_address=%s) connected!", w.host)
+	}
+
+	logrus.Info("@tracker All nodes finishes job")
+	if !startTime.IsZero() {
+		logrus.Infof("@tracker %s secs between node start and job finish", time.Since(startTime))
+	}
+	return nil
+}
+
+// assign sends its rank and links to the worker. The connection is kept open while
+// other workers still have to link to the worker.
+func (t *Tracker) assign(w *worker, rank int, waitConn map[int]*worker, lm *linkMap) error {
+	if _, err := w.assignRank(rank, waitConn, lm); err != nil {
+		w.conn.Close()
+		return fmt.Errorf("failed to assign rank %d to %s: %v", rank, w.host, err)
+	}
+	if w.waitAccept > 0 {
+		waitConn[rank] = w
+	} else {
+		w.conn.Close()
+	}
+	return nil
+}
+
+func remove(ranks []int, rank int) []int {
+	for i, r := range ranks {
+		if r == rank {
+			return append(ranks[:i], ranks[i+1:]...)
+		}
+	}
+	return ranks
+}
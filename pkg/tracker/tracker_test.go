@@ -0,0 +1,316 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracker
+
+import (
+	"bytes"
+	"net"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	v1xgboost "github.com/kubeflow/xgboost-operator/pkg/apis/xgboostjob/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGetLinkMap(t *testing.T) {
+	// Same topology as the one computed by the DMLC tracker.py for 4 workers.
+	lm := getLinkMap(4)
+	expected := &linkMap{
+		tree:   map[int][]int{0: {1, 3}, 1: {0, 2}, 2: {1}, 3: {0}},
+		parent: map[int]int{0: -1, 1: 0, 2: 1, 3: 0},
+		ring:   map[int][2]int{0: {3, 1}, 1: {0, 2}, 2: {1, 3}, 3: {2, 0}},
+	}
+	if !reflect.DeepEqual(lm, expected) {
+		t.Errorf("Got link map %v. Expected %v", lm, expected)
+	}
+
+	for n := 1; n <= 16; n++ {
+		lm := getLinkMap(n)
+		if lm.parent[0] != -1 {
+			t.Errorf("n=%d: got parent %d of rank 0. Expected -1", n, lm.parent[0])
+		}
+		for r := 0; r < n; r++ {
+			if lm.ring[r][1] != (r+1)%n || lm.ring[(r+1)%n][0] != r {
+				t.Errorf("n=%d: rank %d is not followed by rank %d in ring %v", n, r, (r+1)%n, lm.ring)
+			}
+			if r > 0 && !contains(lm.tree[lm.parent[r]], r) {
+				t.Errorf("n=%d: rank %d is not linked to its parent %d in tree %v", n, r, lm.parent[r], lm.tree)
+			}
+		}
+	}
+}
+
+// fakeWorker speaks the worker side of the tracker protocol.
+type fakeWorker struct {
+	*worker
+}
+
+func dialWorker(t *testing.T, addr string, rank, worldSize int, cmd string) *fakeWorker {
+	conn, err := net.DialTimeout("tcp", addr, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to connect to tracker: %v", err)
+	}
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+	w := &fakeWorker{&worker{conn: conn}}
+	if err := w.sendInts(magic, rank, worldSize); err != nil {
+		t.Fatalf("Failed to send header: %v", err)
+	}
+	if err := w.sendStr("NULL"); err != nil {
+		t.Fatalf("Failed to send job id: %v", err)
+	}
+	if err := w.sendStr(cmd); err != nil {
+		t.Fatalf("Failed to send command: %v", err)
+	}
+	if m, err := w.recvInt(); err != nil || m != magic {
+		t.Fatalf("Got magic %d, %v. Expected %d", m, err, magic)
+	}
+	return w
+}
+
+// start runs the start command and returns the rank, world size and links sent by the tracker.
+func (w *fakeWorker) start(port int) (int, int, []int, error) {
+	rank, err := w.recvInt()
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	if _, err = w.recvInt(); err != nil {
+		return 0, 0, nil, err
+	}
+	worldSize, err := w.recvInt()
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	numNeighbors, err := w.recvInt()
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	var links []int
+	for i := 0; i < numNeighbors+2; i++ {
+		r, err := w.recvInt()
+		if err != nil {
+			return 0, 0, nil, err
+		}
+		if r != -1 && !contains(links, r) {
+			links = append(links, r)
+		}
+	}
+
+	// Report no good link, read the workers to connect to, then report no error.
+	if err := w.sendInt(0); err != nil {
+		return 0, 0, nil, err
+	}
+	numConn, err := w.recvInt()
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	if _, err := w.recvInt(); err != nil {
+		return 0, 0, nil, err
+	}
+	for i := 0; i < numConn; i++ {
+		if _, err := w.recvStr(); err != nil {
+			return 0, 0, nil, err
+		}
+		if _, err := w.recvInt(); err != nil {
+			return 0, 0, nil, err
+		}
+		if _, err := w.recvInt(); err != nil {
+			return 0, 0, nil, err
+		}
+	}
+	if err := w.sendInts(0, port); err != nil {
+		return 0, 0, nil, err
+	}
+	sort.Ints(links)
+	return rank, worldSize, links, nil
+}
+
+func TestTrackerRun(t *testing.T) {
+	type tc struct {
+		numWorkers int
+		ranks      []int
+	}
+	testCase := []tc{
+		tc{numWorkers: 1, ranks: []int{0}},
+		tc{numWorkers: 3, ranks: []int{-1, -1, -1}},
+		tc{numWorkers: 4, ranks: []int{2, 0, 3, 1}},
+	}
+	for _, c := range testCase {
+		tr, err := New("127.0.0.1:0", c.numWorkers)
+		if err != nil {
+			t.Fatalf("Failed to start tracker: %v", err)
+		}
+		addr := tr.Addr().String()
+		evals := make(chan int, 1)
+		tr.OnEval = func(iteration int, metrics map[string]float64) { evals <- iteration }
+		done := make(chan error, 1)
+		go func() { done <- tr.Run() }()
+
+		type result struct {
+			rank, worldSize int
+			links           []int
+			err             error
+		}
+		results := make(chan result, len(c.ranks))
+		for i, rank := range c.ranks {
+			w := dialWorker(t, addr, rank, c.numWorkers, cmdStart)
+			go func(w *fakeWorker, port int) {
+				defer w.conn.Close()
+				rank, worldSize, links, err := w.start(port)
+				results <- result{rank, worldSize, links, err}
+			}(w, 9000+i)
+		}
+
+		lm := getLinkMap(c.numWorkers)
+		assigned := map[int]bool{}
+		for range c.ranks {
+			res := <-results
+			if res.err != nil {
+				t.Fatalf("Worker failed to start: %v", res.err)
+			}
+			if res.worldSize != c.numWorkers {
+				t.Errorf("Got world size %d. Expected %d", res.worldSize, c.numWorkers)
+			}
+			expectedLinks := append([]int{}, lm.tree[res.rank]...)
+			for _, r := range lm.ring[res.rank] {
+				if r != res.rank && !contains(expectedLinks, r) {
+					expectedLinks = append(expectedLinks, r)
+				}
+			}
+			sort.Ints(expectedLinks)
+			if len(expectedLinks) == 0 {
+				expectedLinks = nil
+			}
+			if !reflect.DeepEqual(res.links, expectedLinks) {
+				t.Errorf("Rank %d got links %v. Expected %v", res.rank, res.links, expectedLinks)
+			}
+			assigned[res.rank] = true
+		}
+		if len(assigned) != c.numWorkers {
+			t.Errorf("Got ranks %v. Expected %d distinct ranks", assigned, c.numWorkers)
+		}
+
+		w := dialWorker(t, addr, -1, -1, cmdPrint)
+		w.sendStr("hello from a worker\n")
+		w.conn.Close()
+		w = dialWorker(t, addr, -1, -1, cmdPrint)
+		w.sendStr("[7]\teval-auc:0.91\n")
+		w.conn.Close()
+		select {
+		case iteration := <-evals:
+			if iteration != 7 {
+				t.Errorf("Got evaluation of iteration %d. Expected 7", iteration)
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatalf("Tracker did not report the evaluation printed by a worker")
+		}
+		for rank := range assigned {
+			dialWorker(t, addr, rank, -1, cmdShutdown).conn.Close()
+		}
+
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Errorf("Tracker failed: %v", err)
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatalf("Tracker did not exit after all workers shut down")
+		}
+	}
+}
+
+func TestParseEvalLog(t *testing.T) {
+	type tc struct {
+		line              string
+		expectedOK        bool
+		expectedIteration int
+		expectedMetrics   map[string]float64
+	}
+	testCase := []tc{
+		tc{line: "[42]\teval-auc:0.91\ttrain-auc:0.95\n", expectedOK: true, expectedIteration: 42,
+			expectedMetrics: map[string]float64{"eval-auc": 0.91, "train-auc": 0.95}},
+		// xgboost.cv
+		tc{line: "[3]\ttrain-rmse:0.5+0.01\ttest-rmse:0.6+0.02", expectedOK: true, expectedIteration: 3,
+			expectedMetrics: map[string]float64{"train-rmse": 0.5, "test-rmse": 0.6}},
+		tc{line: "[LightGBM] [Info] Iteration:10, valid_1 auc : 0.95\n", expectedOK: true, expectedIteration: 10,
+			expectedMetrics: map[string]float64{"valid_1-auc": 0.95}},
+		tc{line: "[LightGBM] [Info] Iteration:10, training binary_logloss : 0.3", expectedOK: true, expectedIteration: 10,
+			expectedMetrics: map[string]float64{"training-binary_logloss": 0.3}},
+		tc{line: "[LightGBM] [Info] Finished loading data", expectedOK: false},
+		tc{line: "[LightGBM] [Info] Iteration:10, valid_1 auc : nan%", expectedOK: false},
+		tc{line: "[10] training", expectedOK: false},
+		tc{line: "hello from a worker", expectedOK: false},
+	}
+	for i, c := range testCase {
+		iteration, metrics, ok := ParseEvalLog(c.line)
+		if ok != c.expectedOK || iteration != c.expectedIteration || !reflect.DeepEqual(metrics, c.expectedMetrics) {
+			t.Errorf("Case %d: Got %d, %v, %v. Expected %d, %v, %v", i, iteration, metrics, ok,
+				c.expectedIteration, c.expectedMetrics, c.expectedOK)
+		}
+	}
+}
+
+func TestMetricsReporter(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-xgboostjob-master-0", Namespace: metav1.NamespaceDefault}}
+	client := fake.NewSimpleClientset(pod)
+	r := NewMetricsReporter(client, pod.Namespace, pod.Name, time.Hour)
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		r.Run(stop)
+		close(done)
+	}()
+
+	// Only the metrics of the last iteration are patched when the reporter stops, those
+	// printed by LightGBM on a line per metric are merged.
+	r.Report(1, map[string]float64{"eval-auc": 0.8})
+	r.Report(2, map[string]float64{"eval-auc": 0.85})
+	log := "[LightGBM] [Info] Iteration:3, valid_1 auc : 0.9\n[LightGBM] [Info] Iteration:3, valid_1 binary_logloss : 0.3\n"
+	out := &bytes.Buffer{}
+	if err := ReportEvalLog(strings.NewReader(log), out, r.Report); err != nil {
+		t.Fatalf("Failed to report the evaluation log: %v", err)
+	}
+	if out.String() != log {
+		t.Errorf("Got log %q. Expected %q", out.String(), log)
+	}
+	close(stop)
+	<-done
+
+	latest, err := client.CoreV1().Pods(pod.Namespace).Get(pod.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get the pod: %v", err)
+	}
+	expected := `{"iteration":3,"metrics":{"valid_1-auc":0.9,"valid_1-binary_logloss":0.3}}`
+	if annotation := latest.Annotations[v1xgboost.TrainingMetricsAnnotation]; annotation != expected {
+		t.Errorf("Got annotation %s. Expected %s", annotation, expected)
+	}
+	if patches := len(client.Actions()) - 1; patches != 1 {
+		t.Errorf("Got %d patches. Expected 1", patches)
+	}
+}
+
+func contains(ranks []int, rank int) bool {
+	for _, r := range ranks {
+		if r == rank {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,246 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracker
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+)
+
+// magic is exchanged by the tracker and a worker when the worker connects.
+const magic = 0xff99
+
+// Commands sent by the workers.
+const (
+	cmdStart    = "start"
+	cmdRecover  = "recover"
+	cmdPrint    = "print"
+	cmdShutdown = "shutdown"
+)
+
+// worker is a connection from a Rabit worker. Integers are sent as 32 bit little endian
+// and strings are prefixed with their length.
+type worker struct {
+	conn net.Conn
+	host string
+
+	rank      int
+	worldSize int
+	jobID     string
+	cmd       string
+
+	// waitAccept is the number of links the worker still waits other workers to open.
+	waitAccept int
+	// port is where the worker accepts links from other workers.
+	port int
+}
+
+// newWorker reads the header sent by a worker when it connects.
+func newWorker(conn net.Conn) (*worker, error) {
+	host, _, err := net.SplitHostPort(conn.RemoteAddr().String())
+	if err != nil {
+		return nil, err
+	}
+	w := &worker{conn: conn, host: host}
+
+	m, err := w.recvInt()
+	if err != nil {
+		return nil, err
+	}
+	if m != magic {
+		return nil, fmt.Errorf("invalid magic number %d from %s", m, host)
+	}
+	if err := w.sendInt(magic); err != nil {
+		return nil, err
+	}
+	if w.rank, err = w.recvInt(); err != nil {
+		return nil, err
+	}
+	if w.worldSize, err = w.recvInt(); err != nil {
+		return nil, err
+	}
+	if w.jobID, err = w.recvStr(); err != nil {
+		return nil, err
+	}
+	if w.cmd, err = w.recvStr(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// decideRank returns the rank of the worker, or -1 if the tracker has to assign one.
+func (w *worker) decideRank(jobMap map[string]int) int {
+	if w.rank >= 0 {
+		return w.rank
+	}
+	if rank, ok := jobMap[w.jobID]; ok && w.jobID != "NULL" {
+		return rank
+	}
+	return -1
+}
+
+// assignRank sends the rank and the links of the worker, then tells it the address of
+// the linked workers that are waiting for it until it could open all its links. It
+// returns the ranks that do not wait for any link anymore.
+func (w *worker) assignRank(rank int, waitConn map[int]*worker, lm *linkMap) ([]int, error) {
+	w.rank = rank
+	neighbors := map[int]bool{}
+	for _, r := range lm.tree[rank] {
+		neighbors[r] = true
+	}
+	prev, next := lm.ring[rank][0], lm.ring[rank][1]
+
+	if err := w.sendInts(rank, lm.parent[rank], len(lm.tree), len(neighbors)); err != nil {
+		return nil, err
+	}
+	for _, r := range sortedKeys(neighbors) {
+		if err := w.sendInt(r); err != nil {
+			return nil, err
+		}
+	}
+	for _, r := range []int{prev, next} {
+		if r == -1 || r == rank {
+			r = -1
+		} else {
+			neighbors[r] = true
+		}
+		if err := w.sendInt(r); err != nil {
+			return nil, err
+		}
+	}
+
+	for {
+		numGood, err := w.recvInt()
+		if err != nil {
+			return nil, err
+		}
+		good := map[int]bool{}
+		for i := 0; i < numGood; i++ {
+			r, err := w.recvInt()
+			if err != nil {
+				return nil, err
+			}
+			if !neighbors[r] {
+				return nil, fmt.Errorf("worker %d reported a link to %d which is not its neighbor", rank, r)
+			}
+			good[r] = true
+		}
+
+		var bad, conn []int
+		for _, r := range sortedKeys(neighbors) {
+			if good[r] {
+				continue
+			}
+			bad = append(bad, r)
+			if _, ok := waitConn[r]; ok {
+				conn = append(conn, r)
+			}
+		}
+		if err := w.sendInts(len(conn), len(bad)-len(conn)); err != nil {
+			return nil, err
+		}
+		for _, r := range conn {
+			if err := w.sendStr(waitConn[r].host); err != nil {
+				return nil, err
+			}
+			if err := w.sendInts(waitConn[r].port, r); err != nil {
+				return nil, err
+			}
+		}
+
+		numErr, err := w.recvInt()
+		if err != nil {
+			return nil, err
+		}
+		if numErr != 0 {
+			continue
+		}
+		if w.port, err = w.recvInt(); err != nil {
+			return nil, err
+		}
+
+		// All links of the worker are set up.
+		var done []int
+		for _, r := range conn {
+			waitConn[r].waitAccept--
+			if waitConn[r].waitAccept == 0 {
+				done = append(done, r)
+			}
+		}
+		for _, r := range done {
+			waitConn[r].conn.Close()
+			delete(waitConn, r)
+		}
+		w.waitAccept = len(bad) - len(conn)
+		return done, nil
+	}
+}
+
+func (w *worker) recvInt() (int, error) {
+	var n int32
+	if err := binary.Read(w.conn, binary.LittleEndian, &n); err != nil {
+		return 0, err
+	}
+	return int(n), nil
+}
+
+func (w *worker) sendInt(n int) error {
+	return binary.Write(w.conn, binary.LittleEndian, int32(n))
+}
+
+func (w *worker) sendInts(ns ...int) error {
+	for _, n := range ns {
+		if err := w.sendInt(n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *worker) recvStr() (string, error) {
+	n, err := w.recvInt()
+	if err != nil {
+		return "", err
+	}
+	if n < 0 {
+		return "", fmt.Errorf("invalid string length %d from %s", n, w.host)
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(w.conn, buf); err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
+
+func (w *worker) sendStr(s string) error {
+	if err := w.sendInt(len(s)); err != nil {
+		return err
+	}
+	_, err := w.conn.Write([]byte(s))
+	return err
+}
+
+func sortedKeys(m map[int]bool) []int {
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
@@ -0,0 +1,58 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xgboostjob
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	v1xgboost "github.com/kubeflow/xgboost-operator/pkg/apis/xgboostjob/v1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// +kubebuilder:webhook:path=/mutate-xgboostjob-kubeflow-org-v1-xgboostjob,mutating=true,failurePolicy=fail,groups=xgboostjob.kubeflow.org,resources=xgboostjobs,verbs=create;update,versions=v1,name=mxgboostjob.kubeflow.org
+
+// mutatingHandler sets the defaults of XGBoostJobs, so the stored object
+// reflects what the controller runs.
+type mutatingHandler struct {
+	decoder *admission.Decoder
+}
+
+var _ admission.Handler = &mutatingHandler{}
+var _ admission.DecoderInjector = &mutatingHandler{}
+
+// Handle defaults the XGBoostJob in the admission request.
+func (h *mutatingHandler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	job := &v1xgboost.XGBoostJob{}
+	if err := h.decoder.Decode(req, job); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	v1xgboost.SetDefaults_XGBoostJob(job)
+
+	marshaled, err := json.Marshal(job)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	return admission.PatchResponseFromRaw(req.Object.Raw, marshaled)
+}
+
+// InjectDecoder injects the decoder.
+func (h *mutatingHandler) InjectDecoder(d *admission.Decoder) error {
+	h.decoder = d
+	return nil
+}
@@ -0,0 +1,78 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xgboostjob
+
+import (
+	"context"
+	"net/http"
+
+	v1xgboost "github.com/kubeflow/xgboost-operator/pkg/apis/xgboostjob/v1"
+	"github.com/kubeflow/xgboost-operator/pkg/apis/xgboostjob/validation"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// +kubebuilder:webhook:path=/validate-xgboostjob-kubeflow-org-v1-xgboostjob,mutating=false,failurePolicy=fail,groups=xgboostjob.kubeflow.org,resources=xgboostjobs,verbs=create;update,versions=v1,name=vxgboostjob.kubeflow.org
+
+// validatingHandler rejects XGBoostJobs the controller is not able to run.
+type validatingHandler struct {
+	decoder *admission.Decoder
+}
+
+var _ admission.Handler = &validatingHandler{}
+var _ admission.DecoderInjector = &validatingHandler{}
+
+// Handle validates the XGBoostJob in the admission request.
+func (h *validatingHandler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	job := &v1xgboost.XGBoostJob{}
+	if err := h.decoder.Decode(req, job); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	var errs field.ErrorList
+	switch req.Operation {
+	case admissionv1beta1.Create:
+		errs = validation.ValidateV1XGBoostJob(job)
+	case admissionv1beta1.Update:
+		oldJob := &v1xgboost.XGBoostJob{}
+		if err := h.decoder.DecodeRaw(req.OldObject, oldJob); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		errs = validation.ValidateV1XGBoostJobUpdate(job, oldJob)
+	}
+
+	if len(errs) > 0 {
+		log.Info("rejected XGBoostJob", "namespace", job.Namespace, "name", job.Name, "errors", errs.ToAggregate().Error())
+		return denied(job, errs)
+	}
+	return admission.Allowed("")
+}
+
+// InjectDecoder injects the decoder.
+func (h *validatingHandler) InjectDecoder(d *admission.Decoder) error {
+	h.decoder = d
+	return nil
+}
+
+// denied returns a response carrying the field errors as an Invalid status.
+func denied(job *v1xgboost.XGBoostJob, errs field.ErrorList) admission.Response {
+	status := apierrors.NewInvalid(v1xgboost.SchemeGroupVersionKind.GroupKind(), job.Name, errs).ErrStatus
+	resp := admission.Denied(string(status.Reason))
+	resp.Result = &status
+	return resp
+}
@@ -0,0 +1,43 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xgboostjob
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/conversion"
+)
+
+const (
+	// mutatingWebhookPath is the path the defaulting webhook of XGBoostJob is served at.
+	mutatingWebhookPath = "/mutate-xgboostjob-kubeflow-org-v1-xgboostjob"
+	// validatingWebhookPath is the path the validating webhook of XGBoostJob is served at.
+	validatingWebhookPath = "/validate-xgboostjob-kubeflow-org-v1-xgboostjob"
+	// conversionWebhookPath is the path the conversion webhook between XGBoostJob versions is served at.
+	conversionWebhookPath = "/convert"
+)
+
+var log = logf.Log.WithName("webhook")
+
+// Add registers the XGBoostJob webhooks to the webhook server of the Manager.
+func Add(mgr manager.Manager) error {
+	server := mgr.GetWebhookServer()
+	server.Register(mutatingWebhookPath, &webhook.Admission{Handler: &mutatingHandler{}})
+	server.Register(validatingWebhookPath, &webhook.Admission{Handler: &validatingHandler{}})
+	server.Register(conversionWebhookPath, &conversion.Webhook{})
+	return nil
+}